@@ -0,0 +1,10 @@
+package assets
+
+import (
+	"embed"
+)
+
+// Demo holds the demo levels and their textures, so the binary runs the demo without the assets directory around.
+//
+//go:embed demo
+var Demo embed.FS
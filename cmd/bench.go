@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rebay1982/redcaster/internal/bench"
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/metrics"
+)
+
+// runBenchmark draws frames along a camera path without opening a window and prints their statistics. Without a path
+// file, the camera turns a full circle from the player's starting position.
+func runBenchmark(appConfig config.AppConfig, levelData data.LevelData, mapMode data.MapMode) error {
+	path := bench.NewTurnPath(levelData.GetPlayerCoordData())
+	if appConfig.PathFile != "" {
+		loadedPath, err := bench.LoadCameraPath(appConfig.PathFile)
+		if err != nil {
+			return fmt.Errorf("Failed to load camera path %s: %w", appConfig.PathFile, err)
+		}
+		if err := loadedPath.Validate(levelData); err != nil {
+			return fmt.Errorf("Invalid camera path %s: %w", appConfig.PathFile, err)
+		}
+		path = loadedPath
+	}
+
+	recorder := metrics.NewRecorder()
+	if appConfig.MetricsFile != "" {
+		if err := recorder.StartDump(appConfig.MetricsFile, appConfig.MetricsInterval); err != nil {
+			return err
+		}
+	}
+
+	benchmark := bench.NewBenchmark(appConfig, levelData, mapMode, path, recorder)
+	defer benchmark.Close()
+
+	stats, err := benchmark.Run(appConfig.Frames)
+	if closeErr := recorder.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return bench.WriteReport(os.Stdout, stats)
+}
@@ -6,10 +6,13 @@ import (
 	"runtime/pprof"
 	"time"
 
+	"github.com/rebay1982/redcaster/assets"
 	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
 	"github.com/rebay1982/redcaster/internal/game"
+	"github.com/rebay1982/redcaster/internal/hud"
 	"github.com/rebay1982/redcaster/internal/input"
+	"github.com/rebay1982/redcaster/internal/metrics"
 	"github.com/rebay1982/redcaster/internal/render"
 	"github.com/rebay1982/redcaster/internal/texture"
 
@@ -26,22 +29,154 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	loader := data.NewDataLoader()
-	levelData, err := loader.LoadLevelData(appConfig.DataFile)
+	loader := data.NewDataLoader(appConfig.AssetPaths...)
+	levelData, err := loadLevel(loader, appConfig.DataFile)
 	if err != nil {
 		fmt.Printf("Failed to load data file %s. Aborting.\n", appConfig.DataFile)
 		fmt.Printf("Caused by %v.\n", err)
 		os.Exit(1)
 	}
 
+	if appConfig.Camera != nil {
+		levelData.PlayerCoordData = data.PlayerCoordData{
+			PlayerX:     appConfig.Camera.X,
+			PlayerY:     appConfig.Camera.Y,
+			PlayerAngle: appConfig.Camera.Angle,
+		}
+	}
+
+	mapMode, ok := data.ParseMapMode(appConfig.MapMode)
+	if !ok {
+		fmt.Printf("Unknown map mode %s, expected off, minimap or automap. Aborting.\n", appConfig.MapMode)
+		os.Exit(1)
+	}
+
+	if appConfig.Mode == config.MODE_RENDER {
+		if err := renderScreenshot(appConfig, levelData, mapMode); err != nil {
+			fmt.Printf("Failed to render screenshot %s.\n", appConfig.OutputFile)
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if appConfig.Mode == config.MODE_BENCH {
+		if err := runBenchmark(appConfig, levelData, mapMode); err != nil {
+			fmt.Printf("Failed to run benchmark.\n")
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	inputHandler := input.NewInputHandler()
-	game := game.NewGame(levelData, inputHandler)
+	if appConfig.BindingsFile != "" {
+		bindings, err := input.LoadBindings(appConfig.BindingsFile)
+		if err != nil {
+			fmt.Printf("Failed to load bindings file %s. Aborting.\n", appConfig.BindingsFile)
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+		inputHandler.SetBindings(bindings)
+	}
+
+	// Replays start from the recorded game, recordings from the game about to start.
+	var recordingFile *os.File
+	if appConfig.Mode == config.MODE_REPLAY {
+		if appConfig.RecordFile == "" {
+			fmt.Printf("Replay mode needs a recording, set with -record. Aborting.\n")
+			os.Exit(1)
+		}
+
+		recording, err := input.LoadRecording(appConfig.RecordFile)
+		if err != nil {
+			fmt.Printf("Failed to load recording %s. Aborting.\n", appConfig.RecordFile)
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+
+		if recording.Header.Level != appConfig.DataFile {
+			fmt.Printf("WARN: Recording made on level %s, replaying it on %s.\n", recording.Header.Level,
+				appConfig.DataFile)
+		}
+
+		appConfig.GameConfig, levelData.PlayerCoordData = recordedGame(recording.Header)
+		inputHandler.StartReplay(recording)
+	} else if appConfig.RecordFile != "" {
+		recordingFile, err = os.Create(appConfig.RecordFile)
+		if err == nil {
+			err = inputHandler.StartRecording(recordingFile, newRecordingHeader(appConfig, levelData))
+		}
+		if err != nil {
+			fmt.Printf("Failed to record input to %s. Aborting.\n", appConfig.RecordFile)
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+	}
+
+	game := game.NewGame(appConfig.GameConfig, levelData, inputHandler)
+	game.SetMapMode(mapMode)
 
 	renderConfiguration := appConfig.RenderConfig
 
 	textureManager := texture.NewTextureManager(renderConfiguration, levelData)
 	renderer := render.NewRenderer(renderConfiguration, &game, &textureManager, levelData)
 
+	headsUpDisplay := hud.NewHUD(renderConfiguration)
+	if levelData.Name != "" {
+		headsUpDisplay.ShowMessage(levelData.Name, hud.MESSAGE_DURATION)
+	}
+	if appConfig.Mode == config.MODE_REPLAY {
+		headsUpDisplay.ShowMessage("Replaying "+appConfig.RecordFile, hud.MESSAGE_DURATION)
+	}
+
+	// Frames are only timed when the frame rate is displayed or the metrics are written.
+	var recorder *metrics.Recorder
+	if renderConfiguration.IsDisplayFpsEnabled() || appConfig.MetricsFile != "" {
+		recorder = metrics.NewRecorder()
+		renderer.SetMetricsRecorder(recorder)
+	}
+
+	if appConfig.MetricsFile != "" {
+		if err := recorder.StartDump(appConfig.MetricsFile, appConfig.MetricsInterval); err != nil {
+			fmt.Printf("Failed to write metrics file %s. Aborting.\n", appConfig.MetricsFile)
+			fmt.Printf("Caused by %v.\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The HUD is drawn over the world, from the same snapshot.
+	draw := func() []uint8 {
+		if recorder != nil {
+			recorder.BeginFrame()
+		}
+
+		frameBuffer := renderer.Draw()
+		snapshot := renderer.GetFrameSnapshot()
+
+		status := hud.Status{
+			Player:  snapshot.Player,
+			Elapsed: snapshot.Elapsed,
+			Health:  hud.PLACEHOLDER_HEALTH,
+			Ammo:    hud.PLACEHOLDER_AMMO,
+		}
+		if recorder != nil && renderConfiguration.IsDisplayFpsEnabled() {
+			status.Fps = recorder.GetFps()
+		}
+
+		start := time.Now()
+		headsUpDisplay.Draw(frameBuffer, status)
+
+		if recorder != nil {
+			recorder.Time(metrics.STAGE_OVERLAY, start)
+			if err := recorder.EndFrame(); err != nil {
+				fmt.Printf("Failed to write metrics file %s, caused by %v.\n", appConfig.MetricsFile, err)
+			}
+		}
+
+		return frameBuffer
+	}
+
 	winConfig := rp.WindowConfig{
 		Title:     appConfig.WindowTitle,
 		Width:     renderConfiguration.GetFbWidth(),
@@ -50,23 +185,41 @@ func main() {
 		VSync:     false,
 	}
 
-	// Update goroutine.
+	// Update goroutine, runs the simulation at a fixed tick rate.
 	go func() {
-		sinceLastCall := 0
-		var start time.Time
+		last := time.Now()
 		for {
-			start = time.Now()
+			now := time.Now()
 
-			// Careful, we're updating game while the rendering loop is running. Might cause issues.
-			game.Update()
-			sinceLastCall = int(time.Since(start).Nanoseconds())
+			// The renderer only reads the world snapshots published by Update, it's safe to update while rendering.
+			untilNextTick := game.Advance(now.Sub(last))
+			last = now
 
-			time.Sleep(time.Duration(1600000 - sinceLastCall))
+			time.Sleep(untilNextTick)
 		}
 	}()
 
-	rp.Init(winConfig, renderer.Draw, inputHandler.HandleInputEvent)
+	// Input is read from the window's physical keys, redpix only warns that it isn't given an input handler.
+	rp.Init(winConfig, draw, nil)
+	newWindowInput(inputHandler)
 	rp.Run()
+	renderer.Close()
+
+	if recordingFile != nil {
+		err := inputHandler.StopRecording()
+		if closeErr := recordingFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Printf("Failed to record input to %s, caused by %v.\n", appConfig.RecordFile, err)
+		}
+	}
+
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			fmt.Printf("Failed to write metrics file %s, caused by %v.\n", appConfig.MetricsFile, err)
+		}
+	}
 
 	// Memory profile
 	if appConfig.Profile {
@@ -75,3 +228,14 @@ func main() {
 		f.Close()
 	}
 }
+
+// loadLevel loads a level file or package. The default demo level falls back to the copy embedded in the binary when
+// not run from the repository root.
+func loadLevel(loader data.DataLoader, filename string) (data.LevelData, error) {
+	if _, err := os.Stat(filename); err != nil && filename == config.DATA_FILE {
+		fmt.Println("Loading the embedded demo level.")
+		return loader.LoadLevelDataFS(assets.Demo, config.EMBEDDED_DATA_FILE)
+	}
+
+	return loader.LoadLevelData(filename)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/game"
+	"github.com/rebay1982/redcaster/internal/input"
+	"github.com/rebay1982/redcaster/internal/render"
+	"github.com/rebay1982/redcaster/internal/texture"
+)
+
+// renderScreenshot draws a single frame without opening a window and writes it to the configured output file.
+func renderScreenshot(appConfig config.AppConfig, levelData data.LevelData, mapMode data.MapMode) error {
+	renderConfiguration := appConfig.RenderConfig
+
+	game := game.NewGame(appConfig.GameConfig, levelData, input.NewInputHandler())
+	game.SetMapMode(mapMode)
+	textureManager := texture.NewTextureManager(renderConfiguration, levelData)
+	renderer := render.NewRenderer(renderConfiguration, &game, &textureManager, levelData)
+	defer renderer.Close()
+
+	frameBuffer := renderer.Draw()
+
+	err := render.WriteScreenshot(
+		appConfig.OutputFile,
+		frameBuffer,
+		renderConfiguration.GetFbWidth(),
+		renderConfiguration.GetFbHeight(),
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s.\n", appConfig.OutputFile)
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/input"
+)
+
+// newRecordingHeader describes the game about to be recorded, so a replay can start from the same state.
+func newRecordingHeader(appConfig config.AppConfig, levelData data.LevelData) input.RecordingHeader {
+	gameConfig := appConfig.GameConfig
+	player := levelData.GetPlayerCoordData()
+
+	return input.RecordingHeader{
+		Level:            appConfig.DataFile,
+		TickRate:         gameConfig.GetTickRate(),
+		MoveSpeed:        gameConfig.GetMoveSpeed(),
+		TurnSpeed:        gameConfig.GetTurnSpeed(),
+		MouseSensitivity: gameConfig.GetMouseSensitivity(),
+		PlayerRadius:     gameConfig.GetPlayerRadius(),
+		PlayerX:          player.PlayerX,
+		PlayerY:          player.PlayerY,
+		PlayerAngle:      player.PlayerAngle,
+	}
+}
+
+// recordedGame returns the game settings and player position a recording was made with, the command line's are
+// ignored so the replay follows the recorded path.
+func recordedGame(header input.RecordingHeader) (config.GameConfiguration, data.PlayerCoordData) {
+	gameConfig := config.NewGameConfiguration(header.TickRate, header.MoveSpeed, header.TurnSpeed)
+	gameConfig.SetMouseSensitivity(header.MouseSensitivity)
+	gameConfig.SetPlayerRadius(header.PlayerRadius)
+
+	player := data.PlayerCoordData{
+		PlayerX:     header.PlayerX,
+		PlayerY:     header.PlayerY,
+		PlayerAngle: header.PlayerAngle,
+	}
+
+	return gameConfig, player
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/rebay1982/redcaster/internal/input"
+)
+
+// Keys other than letters and digits, by their input key name.
+var glfwNamedKeys = map[glfw.Key]input.Key{
+	glfw.KeySpace:        "space",
+	glfw.KeyTab:          "tab",
+	glfw.KeyEnter:        "enter",
+	glfw.KeyBackspace:    "backspace",
+	glfw.KeyUp:           "up",
+	glfw.KeyDown:         "down",
+	glfw.KeyLeft:         "left",
+	glfw.KeyRight:        "right",
+	glfw.KeyLeftShift:    "leftShift",
+	glfw.KeyRightShift:   "rightShift",
+	glfw.KeyLeftControl:  "leftControl",
+	glfw.KeyRightControl: "rightControl",
+	glfw.KeyLeftAlt:      "leftAlt",
+	glfw.KeyRightAlt:     "rightAlt",
+}
+
+var glfwMouseButtons = map[glfw.MouseButton]input.Key{
+	glfw.MouseButtonLeft:   "mouseLeft",
+	glfw.MouseButtonRight:  "mouseRight",
+	glfw.MouseButtonMiddle: "mouseMiddle",
+}
+
+// windowInput feeds the physical keys, mouse buttons and mouse movement of the window to the input handler. Redpix only
+// reports a few logical keys, the callbacks are installed on its window directly.
+//
+// Clicking in the window captures the mouse for mouse look, escape releases it. Mouse buttons and movement are only
+// handled while the mouse is captured.
+type windowInput struct {
+	window       *glfw.Window
+	inputHandler *input.InputHandler
+
+	captured bool
+	// Last horizontal cursor position, movement is reported as the difference with it.
+	cursorX float64
+}
+
+// newWindowInput starts handling the input of the window created by redpix, which is the current context once redpix
+// is initialized. Redpix must not be given an input handler, it would replace the key callback when it starts running.
+func newWindowInput(inputHandler *input.InputHandler) *windowInput {
+	w := &windowInput{
+		window:       glfw.GetCurrentContext(),
+		inputHandler: inputHandler,
+	}
+	w.window.SetKeyCallback(w.handleKey)
+	w.window.SetMouseButtonCallback(w.handleMouseButton)
+	w.window.SetCursorPosCallback(w.handleCursorPos)
+
+	return w
+}
+
+func (w *windowInput) handleKey(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, _ glfw.ModifierKey) {
+	// The input handler ignores presses of keys already held down.
+	if action == glfw.Repeat {
+		return
+	}
+
+	if key == glfw.KeyEscape {
+		if action == glfw.Press {
+			w.releaseMouse()
+		}
+		return
+	}
+
+	if inputKey, ok := toInputKey(key); ok {
+		w.inputHandler.HandleKeyEvent(inputKey, action == glfw.Press)
+	}
+}
+
+func (w *windowInput) handleMouseButton(_ *glfw.Window, button glfw.MouseButton, action glfw.Action,
+	_ glfw.ModifierKey) {
+	// The click capturing the mouse isn't handled as a button press.
+	if !w.captured {
+		if action == glfw.Press {
+			w.captureMouse()
+		}
+		return
+	}
+
+	if inputKey, ok := glfwMouseButtons[button]; ok {
+		w.inputHandler.HandleKeyEvent(inputKey, action == glfw.Press)
+	}
+}
+
+func (w *windowInput) handleCursorPos(_ *glfw.Window, x, _ float64) {
+	if w.captured {
+		w.inputHandler.HandleMouseMove(x - w.cursorX)
+	}
+	w.cursorX = x
+}
+
+// captureMouse hides the cursor and keeps it in the window, so the mouse can turn indefinitely. Unaccelerated motion is
+// used where supported.
+func (w *windowInput) captureMouse() {
+	w.window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	if glfw.RawMouseMotionSupported() {
+		w.window.SetInputMode(glfw.RawMouseMotion, glfw.True)
+	}
+
+	w.cursorX, _ = w.window.GetCursorPos()
+	w.captured = true
+}
+
+// releaseMouse gives the cursor back, along with the mouse buttons held.
+func (w *windowInput) releaseMouse() {
+	if !w.captured {
+		return
+	}
+
+	w.window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	for _, inputKey := range glfwMouseButtons {
+		w.inputHandler.HandleKeyEvent(inputKey, false)
+	}
+
+	w.captured = false
+}
+
+// toInputKey returns the name of a physical key. GLFW key codes are named after the US layout, like input keys.
+func toInputKey(key glfw.Key) (input.Key, bool) {
+	switch {
+	case key >= glfw.KeyA && key <= glfw.KeyZ:
+		return input.Key(rune('a' + key - glfw.KeyA)), true
+	case key >= glfw.Key0 && key <= glfw.Key9:
+		return input.Key(rune('0' + key - glfw.Key0)), true
+	}
+
+	inputKey, ok := glfwNamedKeys[key]
+	return inputKey, ok
+}
@@ -2,10 +2,13 @@ module github.com/rebay1982/redcaster
 
 go 1.22.5
 
-require github.com/rebay1982/redpix v0.0.2
+require (
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b
+	github.com/rebay1982/redpix v0.0.2
+	golang.org/x/image v0.23.0
+)
 
 require (
 	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6 // indirect
-	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
 )
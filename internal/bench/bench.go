@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/game"
+	"github.com/rebay1982/redcaster/internal/input"
+	"github.com/rebay1982/redcaster/internal/metrics"
+	"github.com/rebay1982/redcaster/internal/render"
+	"github.com/rebay1982/redcaster/internal/texture"
+)
+
+const (
+	// Game clock rate of a benchmark, animated textures advance by one of its frames per frame drawn.
+	BENCH_FRAME_RATE = 60
+	// Frames drawn before measuring, while caches warm up. They start the camera path.
+	BENCH_WARMUP_FRAMES = 10
+)
+
+// cameraWorld shows a level from the camera path's position instead of the player's. The game never ticks, so every
+// run draws the same frames.
+type cameraWorld struct {
+	game     *game.Game
+	snapshot data.WorldSnapshot
+}
+
+func (w *cameraWorld) GetWorldSnapshot() data.WorldSnapshot {
+	return w.snapshot
+}
+
+func (w *cameraWorld) CheckMapCollision(x, y float64) (bool, int) {
+	return w.game.CheckMapCollision(x, y)
+}
+
+// moveCamera places the camera for a frame, the game clock follows the frame number.
+func (w *cameraWorld) moveCamera(frame int, camera data.PlayerCoordData) {
+	w.snapshot.Player = camera
+	w.snapshot.Elapsed = time.Duration(frame) * time.Second / BENCH_FRAME_RATE
+}
+
+// Benchmark draws a level along a camera path without opening a window, timing every frame.
+type Benchmark struct {
+	world    *cameraWorld
+	renderer *render.Renderer
+	recorder *metrics.Recorder
+	path     CameraPath
+}
+
+// NewBenchmark prepares a benchmark of a level. Frames are timed by the recorder, which may already be dumping metrics.
+func NewBenchmark(appConfig config.AppConfig, levelData data.LevelData, mapMode data.MapMode, path CameraPath,
+	recorder *metrics.Recorder) *Benchmark {
+	g := game.NewGame(appConfig.GameConfig, levelData, input.NewInputHandler())
+	g.SetMapMode(mapMode)
+
+	world := &cameraWorld{
+		game:     &g,
+		snapshot: g.GetWorldSnapshot(),
+	}
+
+	textureManager := texture.NewTextureManager(appConfig.RenderConfig, levelData)
+	renderer := render.NewRenderer(appConfig.RenderConfig, world, &textureManager, levelData)
+	renderer.SetMetricsRecorder(recorder)
+
+	return &Benchmark{
+		world:    world,
+		renderer: renderer,
+		recorder: recorder,
+		path:     path,
+	}
+}
+
+// Close stops the benchmark's rendering threads.
+func (b *Benchmark) Close() {
+	b.renderer.Close()
+}
+
+// Run draws frames along the whole camera path, after the warm up frames, and returns their statistics. Returns an
+// error if no frames are requested or if the recorder failed to dump metrics.
+func (b *Benchmark) Run(frames int) (metrics.Stats, error) {
+	if frames < 1 {
+		return metrics.Stats{}, fmt.Errorf("Benchmark needs at least 1 frame, got %d", frames)
+	}
+
+	for frame := 0; frame < BENCH_WARMUP_FRAMES; frame++ {
+		b.world.moveCamera(frame, b.path.At(0.0))
+		b.renderer.Draw()
+	}
+
+	measured := make([]metrics.Frame, 0, frames)
+	for frame := 0; frame < frames; frame++ {
+		progress := 0.0
+		if frames > 1 {
+			progress = float64(frame) / float64(frames-1)
+		}
+		b.world.moveCamera(frame, b.path.At(progress))
+
+		b.recorder.BeginFrame()
+		b.renderer.Draw()
+		if err := b.recorder.EndFrame(); err != nil {
+			return metrics.Stats{}, err
+		}
+
+		measured = append(measured, b.recorder.LastFrame())
+	}
+
+	return metrics.ComputeStats(measured), nil
+}
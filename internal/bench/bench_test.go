@@ -0,0 +1,100 @@
+package bench
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rebay1982/redcaster/assets"
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/metrics"
+)
+
+func Test_BenchmarkRun(t *testing.T) {
+	levelData, err := data.NewDataLoader().LoadLevelDataFS(assets.Demo, config.EMBEDDED_DATA_FILE)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	renderConfig := config.NewRenderConfiguration(320, 240, 60.0, false)
+	renderConfig.SetThreads(2)
+	appConfig := config.AppConfig{
+		RenderConfig: renderConfig,
+		GameConfig:   config.NewGameConfiguration(config.TICK_RATE, config.MOVE_SPEED, config.TURN_SPEED),
+	}
+
+	path := CameraPath{
+		Waypoints: []Waypoint{{X: 5.0, Y: 5.0, Angle: 0.0}, {X: 9.5, Y: 4.5, Angle: 45.0}},
+	}
+
+	benchmark := NewBenchmark(appConfig, levelData, data.MAP_MODE_MINIMAP, path, metrics.NewRecorder())
+	defer benchmark.Close()
+	stats, err := benchmark.Run(5)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if stats.Frames != 5 {
+		t.Errorf("Expected 5 frames, got %d", stats.Frames)
+	}
+	if stats.Min <= 0 || stats.Stages[metrics.STAGE_WALLS] <= 0 {
+		t.Errorf("Expected frames and walls to be timed, got %+v", stats)
+	}
+
+	// The last frame is drawn from the end of the path, on the game clock of its frame number.
+	snapshot := benchmark.renderer.GetFrameSnapshot()
+	expectedPlayer := data.PlayerCoordData{PlayerX: 9.5, PlayerY: 4.5, PlayerAngle: 45.0}
+	if diff := cmp.Diff(expectedPlayer, snapshot.Player); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+	if expectedElapsed := 4 * time.Second / BENCH_FRAME_RATE; snapshot.Elapsed != expectedElapsed {
+		t.Errorf("Expected the game clock at %v, got %v", expectedElapsed, snapshot.Elapsed)
+	}
+	if snapshot.MapMode != data.MAP_MODE_MINIMAP {
+		t.Errorf("Expected the minimap, got %v", snapshot.MapMode)
+	}
+	if _, err := benchmark.Run(0); err == nil {
+		t.Errorf("Expected an error running no frames")
+	}
+}
+
+func Test_WriteReport(t *testing.T) {
+	stats := metrics.Stats{
+		Frames: 600,
+		Fps:    400.0,
+		Min:    2 * time.Millisecond,
+		Avg:    2500 * time.Microsecond,
+		P95:    3 * time.Millisecond,
+		P99:    3250 * time.Microsecond,
+		Max:    4 * time.Millisecond,
+		Stages: [metrics.StageCount]time.Duration{
+			metrics.STAGE_FLOOR:   500 * time.Microsecond,
+			metrics.STAGE_CEILING: 400 * time.Microsecond,
+			metrics.STAGE_WALLS:   time.Millisecond,
+			metrics.STAGE_SPRITES: 100 * time.Microsecond,
+			metrics.STAGE_OVERLAY: 50 * time.Microsecond,
+			metrics.STAGE_PRESENT: time.Microsecond,
+		},
+	}
+
+	expected := "Frames      600\n" +
+		"FPS         400.0\n" +
+		"Frame time  min 2.000ms  avg 2.500ms  p95 3.000ms  p99 3.250ms  max 4.000ms\n" +
+		"Stages, average per frame, summed over the rendering threads:\n" +
+		"  floor     0.500ms\n" +
+		"  ceiling   0.400ms\n" +
+		"  walls     1.000ms\n" +
+		"  sprites   0.100ms\n" +
+		"  overlay   0.050ms\n"
+
+	out := &bytes.Buffer{}
+	if err := WriteReport(out, stats); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
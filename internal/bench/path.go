@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+// Waypoint is a camera position on a path, the angle is in degrees.
+type Waypoint struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+}
+
+// CameraPath is a series of waypoints the camera moves through at constant speed, each segment taking the same number
+// of frames. The angle turns the shortest way between two waypoints, use intermediate waypoints for turns of 180
+// degrees or more.
+type CameraPath struct {
+	Waypoints []Waypoint `json:"waypoints"`
+}
+
+// LoadCameraPath loads a camera path file, a JSON object holding a list of waypoints.
+func LoadCameraPath(filename string) (CameraPath, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return CameraPath{}, err
+	}
+
+	return decodeCameraPath(content)
+}
+
+func decodeCameraPath(content []byte) (CameraPath, error) {
+	path := CameraPath{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&path); err != nil {
+		return CameraPath{}, fmt.Errorf("Failed to decode camera path: %w", err)
+	}
+
+	if len(path.Waypoints) == 0 {
+		return CameraPath{}, fmt.Errorf("Camera path has no waypoints")
+	}
+
+	return path, nil
+}
+
+// Validate checks that every waypoint is on an open cell of a level. Segments may still go through walls.
+func (p CameraPath) Validate(levelData data.LevelData) error {
+	for i, waypoint := range p.Waypoints {
+		if waypoint.X < 0.0 || waypoint.Y < 0.0 || waypoint.X >= float64(levelData.Width) ||
+			waypoint.Y >= float64(levelData.Height) {
+			return fmt.Errorf("Waypoint %d (%g, %g) is outside of the map", i, waypoint.X, waypoint.Y)
+		}
+
+		if levelData.Map[int(waypoint.Y)][int(waypoint.X)] != 0 {
+			return fmt.Errorf("Waypoint %d (%g, %g) is inside a wall", i, waypoint.X, waypoint.Y)
+		}
+	}
+
+	return nil
+}
+
+// NewTurnPath returns a path turning the camera a full circle in place, counterclockwise, from a starting position.
+func NewTurnPath(start data.PlayerCoordData) CameraPath {
+	path := CameraPath{}
+	for quarter := 0; quarter <= 4; quarter++ {
+		path.Waypoints = append(path.Waypoints, Waypoint{
+			X:     start.PlayerX,
+			Y:     start.PlayerY,
+			Angle: start.PlayerAngle + float64(quarter)*90.0,
+		})
+	}
+
+	return path
+}
+
+// At returns the camera position at some progress along the path, from 0 at the first waypoint to 1 at the last.
+func (p CameraPath) At(progress float64) data.PlayerCoordData {
+	segments := len(p.Waypoints) - 1
+	if segments <= 0 {
+		return p.Waypoints[0].toPlayerCoords(0.0)
+	}
+
+	position := min(max(progress, 0.0), 1.0) * float64(segments)
+	segment := min(int(position), segments-1)
+	t := position - float64(segment)
+
+	from := p.Waypoints[segment]
+	to := p.Waypoints[segment+1]
+
+	// Difference between the two angles, between -180 and 180.
+	turn := math.Mod(math.Mod(to.Angle-from.Angle, 360.0)+540.0, 360.0) - 180.0
+
+	return Waypoint{
+		X:     from.X + (to.X-from.X)*t,
+		Y:     from.Y + (to.Y-from.Y)*t,
+		Angle: from.Angle,
+	}.toPlayerCoords(turn * t)
+}
+
+// toPlayerCoords returns the waypoint as player coordinates, turned by some angle. The angle is kept between 0 and 360
+// degrees, as the renderer expects.
+func (w Waypoint) toPlayerCoords(turn float64) data.PlayerCoordData {
+	angle := math.Mod(w.Angle+turn, 360.0)
+	if angle < 0.0 {
+		angle += 360.0
+	}
+
+	return data.PlayerCoordData{
+		PlayerX:     w.X,
+		PlayerY:     w.Y,
+		PlayerAngle: angle,
+	}
+}
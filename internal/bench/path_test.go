@@ -0,0 +1,217 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/rebay1982/redcaster/assets"
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+func Test_CameraPathAt(t *testing.T) {
+	path := CameraPath{
+		Waypoints: []Waypoint{
+			{X: 1.0, Y: 1.0, Angle: 350.0},
+			{X: 3.0, Y: 1.0, Angle: 30.0},
+			{X: 3.0, Y: 5.0, Angle: 270.0},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		path     CameraPath
+		progress float64
+		expected data.PlayerCoordData
+	}{
+		{
+			name:     "start",
+			path:     path,
+			progress: 0.0,
+			expected: data.PlayerCoordData{PlayerX: 1.0, PlayerY: 1.0, PlayerAngle: 350.0},
+		},
+		{
+			name:     "turns_through_zero",
+			path:     path,
+			progress: 0.25,
+			expected: data.PlayerCoordData{PlayerX: 2.0, PlayerY: 1.0, PlayerAngle: 10.0},
+		},
+		{
+			name:     "waypoint",
+			path:     path,
+			progress: 0.5,
+			expected: data.PlayerCoordData{PlayerX: 3.0, PlayerY: 1.0, PlayerAngle: 30.0},
+		},
+		{
+			name:     "turns_clockwise",
+			path:     path,
+			progress: 0.75,
+			expected: data.PlayerCoordData{PlayerX: 3.0, PlayerY: 3.0, PlayerAngle: 330.0},
+		},
+		{
+			name:     "end",
+			path:     path,
+			progress: 1.0,
+			expected: data.PlayerCoordData{PlayerX: 3.0, PlayerY: 5.0, PlayerAngle: 270.0},
+		},
+		{
+			name:     "clamped",
+			path:     path,
+			progress: 1.5,
+			expected: data.PlayerCoordData{PlayerX: 3.0, PlayerY: 5.0, PlayerAngle: 270.0},
+		},
+		{
+			name:     "single_waypoint",
+			path:     CameraPath{Waypoints: []Waypoint{{X: 2.0, Y: 3.0, Angle: -90.0}}},
+			progress: 0.5,
+			expected: data.PlayerCoordData{PlayerX: 2.0, PlayerY: 3.0, PlayerAngle: 270.0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.path.At(tc.progress)
+			if diff := cmp.Diff(tc.expected, got, cmpopts.EquateApprox(0.0, 1e-9)); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_NewTurnPath(t *testing.T) {
+	path := NewTurnPath(data.PlayerCoordData{PlayerX: 2.0, PlayerY: 3.0, PlayerAngle: 45.0})
+
+	// The camera goes all the way around, counterclockwise.
+	angles := []float64{}
+	for _, progress := range []float64{0.0, 0.125, 0.5, 0.875, 1.0} {
+		angles = append(angles, path.At(progress).PlayerAngle)
+	}
+
+	expected := []float64{45.0, 90.0, 225.0, 0.0, 45.0}
+	if diff := cmp.Diff(expected, angles, cmpopts.EquateApprox(0.0, 1e-9)); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
+
+func Test_DecodeCameraPath(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		expected  CameraPath
+		expectErr bool
+	}{
+		{
+			name:    "waypoints",
+			content: `{"waypoints": [{"x": 1.5, "y": 2.5, "angle": 90}, {"x": 3.5, "y": 2.5, "angle": 0}]}`,
+			expected: CameraPath{
+				Waypoints: []Waypoint{{X: 1.5, Y: 2.5, Angle: 90.0}, {X: 3.5, Y: 2.5, Angle: 0.0}},
+			},
+		},
+		{
+			name:      "no_waypoints",
+			content:   `{"waypoints": []}`,
+			expectErr: true,
+		},
+		{
+			name:      "unknown_field",
+			content:   `{"waypoints": [{"x": 1.5, "y": 2.5, "heading": 90}]}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid_json",
+			content:   `{"waypoints": [`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeCameraPath([]byte(tc.content))
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_CameraPathValidate(t *testing.T) {
+	levelData := data.LevelData{
+		Width:  4,
+		Height: 3,
+		Map: [][]int{
+			{1, 1, 1, 1},
+			{1, 0, 2, 1},
+			{1, 1, 1, 1},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		waypoint  Waypoint
+		expectErr bool
+	}{
+		{
+			name:     "open_cell",
+			waypoint: Waypoint{X: 1.5, Y: 1.5},
+		},
+		{
+			name:      "outside",
+			waypoint:  Waypoint{X: -3.0, Y: -3.0},
+			expectErr: true,
+		},
+		{
+			name:      "past_the_edge",
+			waypoint:  Waypoint{X: 4.0, Y: 1.5},
+			expectErr: true,
+		},
+		{
+			name:      "inside_a_wall",
+			waypoint:  Waypoint{X: 2.5, Y: 1.5},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := CameraPath{Waypoints: []Waypoint{{X: 1.5, Y: 1.5}, tc.waypoint}}
+
+			err := path.Validate(levelData)
+			if tc.expectErr && err == nil {
+				t.Errorf("Expected an error")
+			}
+			if !tc.expectErr && err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_LoadCameraPathDemo(t *testing.T) {
+	path, err := LoadCameraPath("../../assets/bench/demo-walk.json")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(path.Waypoints) < 2 {
+		t.Errorf("Expected the demo path to have several waypoints, got %d", len(path.Waypoints))
+	}
+
+	levelData, err := data.NewDataLoader().LoadLevelDataFS(assets.Demo, config.EMBEDDED_DATA_FILE)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := path.Validate(levelData); err != nil {
+		t.Errorf("Expected the demo path to fit the demo level, got %v", err)
+	}
+}
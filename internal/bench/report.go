@@ -0,0 +1,36 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rebay1982/redcaster/internal/metrics"
+)
+
+// WriteReport writes a benchmark's frame time statistics in a human readable form. The present stage is left out, there
+// is nothing to present without a window.
+func WriteReport(w io.Writer, stats metrics.Stats) error {
+	_, err := fmt.Fprintf(w, "Frames      %d\n"+
+		"FPS         %.1f\n"+
+		"Frame time  min %s  avg %s  p95 %s  p99 %s  max %s\n"+
+		"Stages, average per frame, summed over the rendering threads:\n",
+		stats.Frames, stats.Fps,
+		formatMilliseconds(stats.Min), formatMilliseconds(stats.Avg), formatMilliseconds(stats.P95),
+		formatMilliseconds(stats.P99), formatMilliseconds(stats.Max))
+	if err != nil {
+		return err
+	}
+
+	for stage := metrics.STAGE_FLOOR; stage < metrics.STAGE_PRESENT; stage++ {
+		if _, err := fmt.Fprintf(w, "  %-8s  %s\n", stage, formatMilliseconds(stats.Stages[stage])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatMilliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
@@ -2,6 +2,13 @@ package config
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -10,30 +17,162 @@ const (
 	WINDOW_HEIGHT = 480
 	FOV           = 60.0
 	DATA_FILE     = "./assets/demo/demo.json"
+
+	// Path of the demo level within the embedded assets, used when the default data file isn't found.
+	EMBEDDED_DATA_FILE = "demo/demo.json"
+
+	MAX_RAY_DISTANCE = 2048.0
+
+	SCREENSHOT_FILE = "screenshot.png"
+
+	TICK_RATE  = 120
+	MOVE_SPEED = 5.0
+	TURN_SPEED = 180.0
+
+	MOUSE_SENSITIVITY = 0.1
+	PLAYER_RADIUS     = 0.25
+
+	// Frames drawn by a benchmark.
+	BENCH_FRAMES = 600
+
+	// Time between two writes of the frame metrics file.
+	METRICS_INTERVAL = 5 * time.Second
 )
 
+// Application modes, selected by the first command line argument. Playing is the default.
+const (
+	MODE_PLAY   = "play"
+	MODE_RENDER = "render"
+	MODE_BENCH  = "bench"
+	MODE_REPLAY = "replay"
+)
+
+// CameraPosition overrides the player's starting position from the level file.
+type CameraPosition struct {
+	X     float64
+	Y     float64
+	Angle float64
+}
+
 type AppConfig struct {
+	Mode         string
 	WindowTitle  string
 	RenderConfig RenderConfiguration
+	GameConfig   GameConfiguration
 	DataFile     string
+	AssetPaths   []string
+	BindingsFile string
+	MapMode      string
 	Profile      bool
+
+	// Input recording, written in play mode and read in replay mode.
+	RecordFile string
+
+	// Frame metrics, not written when the file is empty.
+	MetricsFile     string
+	MetricsInterval time.Duration
+
+	// Headless rendering
+	OutputFile string
+	Camera     *CameraPosition
+
+	// Benchmark
+	Frames   int
+	PathFile string
 }
 
 func GetAppConfiguration() AppConfig {
+	mode := MODE_PLAY
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case MODE_PLAY, MODE_RENDER, MODE_BENCH, MODE_REPLAY:
+			mode = args[0]
+			args = args[1:]
+		}
+	}
+
+	var camera *CameraPosition
 
 	width := flag.Int("w", WINDOW_WIDTH, "Window width in pixels.")
 	height := flag.Int("h", WINDOW_HEIGHT, "Window height in pixels.")
 	fov := flag.Float64("fov", FOV, "Field of view in degrees.")
-	file := flag.String("f", DATA_FILE, "File containing game data.")
+	file := flag.String("f", DATA_FILE, "File containing game data, a level file or a level package archive or directory.")
+	assetPaths := flag.String("assets", "", "List of directories searched for assets not found next to the level file, "+
+		"separated by the OS path list separator.")
+	bindingsFile := flag.String("bindings", "", "File containing key bindings, defaults are used when empty.")
+	recordFile := flag.String("record", "", "File the input is recorded to in play mode, and replayed from in replay mode.")
+	mapMode := flag.String("map", "off", "Map overlay shown at start: off, minimap or automap.")
 	displayFps := flag.Bool("fps", false, "Enable FPS display.")
 	profile := flag.Bool("p", false, "Enable CPU profiling.")
+	metricsFile := flag.String("metrics", "", "File frame time metrics are periodically written to, as CSV or JSON lines "+
+		"depending on its extension (.csv or .json).")
+	metricsInterval := flag.Duration("metricsinterval", METRICS_INTERVAL, "Time between two writes of the metrics file.")
+	threads := flag.Int("threads", runtime.NumCPU(), "Number of threads rendering the frame buffer.")
+	tickRate := flag.Int("tickrate", TICK_RATE, "Game simulation ticks per second.")
+	moveSpeed := flag.Float64("movespeed", MOVE_SPEED, "Player movement speed in map units per second.")
+	turnSpeed := flag.Float64("turnspeed", TURN_SPEED, "Player turn speed in degrees per second.")
+	mouseSensitivity := flag.Float64("mousesens", MOUSE_SENSITIVITY, "Mouse look sensitivity in degrees per pixel.")
+	playerRadius := flag.Float64("radius", PLAYER_RADIUS, "Player collision radius in map units.")
+	maxRayDistance := flag.Float64("maxdist", MAX_RAY_DISTANCE, "Maximum distance, in map cells, a ray travels before giving up.")
+
+	output := flag.String("o", SCREENSHOT_FILE, "Output PNG file in render mode.")
+	frames := flag.Int("frames", BENCH_FRAMES, "Number of frames drawn in bench mode.")
+	pathFile := flag.String("path", "", "Camera path file in bench mode, the camera turns in place when empty.")
+	flag.Func("camera", "Camera position as x,y,angle, overrides the level's player position.", func(value string) error {
+		parsed, err := parseCameraPosition(value)
+		camera = parsed
+		return err
+	})
+
+	flag.CommandLine.Parse(args)
+
+	renderConfig := NewRenderConfiguration(*width, *height, *fov, *displayFps)
+	renderConfig.SetMaxRayDistance(*maxRayDistance)
+	renderConfig.SetThreads(*threads)
 
-	flag.Parse()
+	gameConfig := NewGameConfiguration(*tickRate, *moveSpeed, *turnSpeed)
+	gameConfig.SetMouseSensitivity(*mouseSensitivity)
+	gameConfig.SetPlayerRadius(*playerRadius)
 
 	return AppConfig{
-		WindowTitle:  WINDOW_TITLE,
-		RenderConfig: NewRenderConfiguration(*width, *height, *fov, *displayFps),
-		DataFile:     *file,
-		Profile:      *profile,
+		Mode:            mode,
+		WindowTitle:     WINDOW_TITLE,
+		RenderConfig:    renderConfig,
+		GameConfig:      gameConfig,
+		DataFile:        *file,
+		AssetPaths:      filepath.SplitList(*assetPaths),
+		BindingsFile:    *bindingsFile,
+		RecordFile:      *recordFile,
+		MapMode:         *mapMode,
+		Profile:         *profile,
+		MetricsFile:     *metricsFile,
+		MetricsInterval: *metricsInterval,
+		OutputFile:      *output,
+		Camera:          camera,
+		Frames:          *frames,
+		PathFile:        *pathFile,
 	}
 }
+
+func parseCameraPosition(value string) (*CameraPosition, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected x,y,angle, got %q", value)
+	}
+
+	coords := [3]float64{}
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid camera coordinate %q", part)
+		}
+		coords[i] = coord
+	}
+
+	return &CameraPosition{
+		X:     coords[0],
+		Y:     coords[1],
+		Angle: coords[2],
+	}, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"time"
+)
+
+type GameConfiguration struct {
+	tickRate  int
+	moveSpeed float64
+	turnSpeed float64
+
+	mouseSensitivity float64
+	playerRadius     float64
+}
+
+// NewGameConfiguration creates a game configuration. The tick rate is in ticks per second, the move speed in map units
+// per second and the turn speed in degrees per second.
+func NewGameConfiguration(tickRate int, moveSpeed, turnSpeed float64) GameConfiguration {
+	return GameConfiguration{
+		tickRate:  max(tickRate, 1),
+		moveSpeed: moveSpeed,
+		turnSpeed: turnSpeed,
+
+		mouseSensitivity: MOUSE_SENSITIVITY,
+		playerRadius:     PLAYER_RADIUS,
+	}
+}
+
+func (g GameConfiguration) GetTickRate() int {
+	return g.tickRate
+}
+
+// GetTickDuration returns the simulated time of a single tick.
+func (g GameConfiguration) GetTickDuration() time.Duration {
+	return time.Second / time.Duration(g.tickRate)
+}
+
+func (g GameConfiguration) GetMoveSpeed() float64 {
+	return g.moveSpeed
+}
+
+func (g GameConfiguration) GetTurnSpeed() float64 {
+	return g.turnSpeed
+}
+
+// GetMouseSensitivity returns the turn, in degrees, per pixel of horizontal mouse movement.
+func (g GameConfiguration) GetMouseSensitivity() float64 {
+	return g.mouseSensitivity
+}
+
+func (g *GameConfiguration) SetMouseSensitivity(sensitivity float64) {
+	g.mouseSensitivity = sensitivity
+}
+
+// GetPlayerRadius returns the radius, in map units, of the circle the player collides with walls as.
+func (g GameConfiguration) GetPlayerRadius() float64 {
+	return g.playerRadius
+}
+
+func (g *GameConfiguration) SetPlayerRadius(radius float64) {
+	g.playerRadius = max(radius, 0.0)
+}
@@ -6,16 +6,20 @@ type RenderConfiguration struct {
 	fieldOfView       float64
 	textureMapping    bool
 	skyTextureMapping bool
+	maxRayDistance    float64
+	threads           int
 
 	displayFps bool
 }
 
 func NewRenderConfiguration(width, height int, fov float64, displayFps bool) RenderConfiguration {
 	return RenderConfiguration{
-		fbWidth:     width,
-		fbHeight:    height,
-		fieldOfView: fov,
-		displayFps:  displayFps,
+		fbWidth:        width,
+		fbHeight:       height,
+		fieldOfView:    fov,
+		maxRayDistance: MAX_RAY_DISTANCE,
+		threads:        1,
+		displayFps:     displayFps,
 	}
 }
 
@@ -35,6 +39,23 @@ func (r RenderConfiguration) GetFieldOfView() float64 {
 	return r.fieldOfView
 }
 
+func (r RenderConfiguration) GetMaxRayDistance() float64 {
+	return r.maxRayDistance
+}
+
+func (r *RenderConfiguration) SetMaxRayDistance(distance float64) {
+	r.maxRayDistance = distance
+}
+
+func (r RenderConfiguration) GetThreads() int {
+	return r.threads
+}
+
+// SetThreads sets the number of threads rendering the frame buffer. At least one thread is always used.
+func (r *RenderConfiguration) SetThreads(threads int) {
+	r.threads = max(threads, 1)
+}
+
 func (r RenderConfiguration) IsTextureMappingEnabled() bool {
 	return r.textureMapping
 }
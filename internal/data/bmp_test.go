@@ -0,0 +1,144 @@
+package data
+
+import (
+	"encoding/binary"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	// Compression methods
+	bmpCompressionRGB       = 0
+	bmpCompressionBitfields = 3
+
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+	bmpV4HeaderSize   = 108
+)
+
+// newTestBMP builds a 2x2 BMP with the given info header size, optional masks or palette and the pixel rows.
+func newTestBMP(headerSize uint32, height int32, bitsPerPixel uint16, compression uint32, extra []byte,
+	rows []byte) []byte {
+	le := binary.LittleEndian
+	pixelOffset := bmpFileHeaderSize + headerSize + uint32(len(extra))
+
+	content := []byte("BM")
+	content = le.AppendUint32(content, pixelOffset+uint32(len(rows)))
+	content = le.AppendUint32(content, 0)
+	content = le.AppendUint32(content, pixelOffset)
+
+	header := make([]byte, headerSize)
+	le.PutUint32(header[0:], headerSize)
+	le.PutUint32(header[4:], 2)
+	le.PutUint32(header[8:], uint32(height))
+	le.PutUint16(header[12:], 1)
+	le.PutUint16(header[14:], bitsPerPixel)
+	le.PutUint32(header[16:], compression)
+	content = append(content, header...)
+	content = append(content, extra...)
+
+	return append(content, rows...)
+}
+
+func Test_DecodeBMP(t *testing.T) {
+	// BGRA masks, stored at the end of the info header.
+	masks := []byte{0x00, 0x00, 0xFF, 0x00, 0x00, 0xFF, 0x00, 0x00, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF}
+
+	// Red and green on the top row, blue and half transparent white on the bottom row.
+	rows32 := []byte{
+		0x00, 0x00, 0xFF, 0xFF, 0x00, 0xFF, 0x00, 0xFF,
+		0xFF, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0x80,
+	}
+
+	bitfields := newTestBMP(bmpV4HeaderSize, -2, 32, bmpCompressionBitfields, nil, rows32)
+	copy(bitfields[bmpFileHeaderSize+bmpInfoHeaderSize:], masks)
+
+	// Headers only, 54 bytes, claiming 0x7FFFFFFF x 0x7FFFFFFF pixels.
+	oversized := newTestBMP(bmpInfoHeaderSize, 0x7FFFFFFF, 32, bmpCompressionRGB, nil, nil)
+	binary.LittleEndian.PutUint32(oversized[bmpFileHeaderSize+4:], 0x7FFFFFFF)
+
+	// A single colour palette, with the second pixel using colour 1.
+	paletted := newTestBMP(bmpInfoHeaderSize, 2, 8, bmpCompressionRGB, make([]byte, 4), []byte{0, 1, 0, 0, 0, 0, 0, 0})
+	binary.LittleEndian.PutUint32(paletted[bmpFileHeaderSize+32:], 1)
+
+	testCases := []struct {
+		name     string
+		content  []byte
+		expected []uint8
+		err      bool
+	}{
+		{
+			name:    "32_bits_top_down_opaque",
+			content: newTestBMP(bmpInfoHeaderSize, -2, 32, bmpCompressionRGB, nil, rows32),
+			expected: []uint8{
+				0xFF, 0x00, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF,
+				0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			},
+		},
+		{
+			name:    "32_bits_v4_alpha_bottom_up",
+			content: newTestBMP(bmpV4HeaderSize, 2, 32, bmpCompressionRGB, nil, rows32),
+			expected: []uint8{
+				0x00, 0x00, 0xFF, 0xFF, 0x80, 0x80, 0x80, 0x80,
+				0xFF, 0x00, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF,
+			},
+		},
+		{
+			name:    "32_bits_bitfields",
+			content: bitfields,
+			expected: []uint8{
+				0xFF, 0x00, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF,
+				0x00, 0x00, 0xFF, 0xFF, 0x80, 0x80, 0x80, 0x80,
+			},
+		},
+		{
+			name:    "unsupported_masks",
+			content: newTestBMP(bmpV4HeaderSize, 2, 32, bmpCompressionBitfields, nil, rows32),
+			err:     true,
+		},
+		{
+			name:    "unsupported_compression",
+			content: newTestBMP(bmpInfoHeaderSize, 2, 24, 1, nil, rows32),
+			err:     true,
+		},
+		{
+			name:    "truncated_pixels",
+			content: newTestBMP(bmpInfoHeaderSize, 2, 32, bmpCompressionRGB, nil, rows32[:12]),
+			err:     true,
+		},
+		{
+			name:    "oversized",
+			content: oversized,
+			err:     true,
+		},
+		{
+			name:    "palette_index_out_of_range",
+			content: paletted,
+			err:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := NewTextureLoaderFS(fstest.MapFS{"texture.bmp": {Data: tc.content}})
+			texture, err := loader.loadTexture("texture.bmp")
+
+			if tc.err {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Not expecting error, got %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, texture.Data); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
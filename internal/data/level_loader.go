@@ -2,27 +2,68 @@ package data
 
 import (
 	"encoding/json"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
-type DataLoader struct{}
+type DataLoader struct {
+	assetSearchPaths []string
 
-func NewDataLoader() DataLoader {
-	return DataLoader{}
+	// File system the level is loaded from, the OS file system when nil.
+	fsys fs.FS
 }
 
+// NewDataLoader creates a data loader. Asset paths in level files are relative to the level file's directory, the
+// optional search paths are looked into, in order, for assets that aren't found there.
+func NewDataLoader(assetSearchPaths ...string) DataLoader {
+	return DataLoader{
+		assetSearchPaths: assetSearchPaths,
+	}
+}
+
+// LoadLevelData loads a level file, a level package archive or a level package directory.
 func (dl DataLoader) LoadLevelData(filename string) (LevelData, error) {
 	loadedData := LevelData{}
 
+	if strings.EqualFold(filepath.Ext(filename), PACKAGE_EXTENSION) {
+		return dl.LoadPackage(filename)
+	}
+
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		return dl.LoadPackageFS(os.DirFS(filename))
+	}
+
 	dataFileContent, err := os.ReadFile(filename)
 	if err != nil {
 		return loadedData, err
 	}
 
-	return dl.decodeLevelDataFile(dataFileContent)
+	return dl.decodeLevelDataFile(dataFileContent, filepath.Dir(filename))
+}
+
+// LoadLevelDataFS loads a level file from the given file system, an embedded one for instance. The level's assets are
+// looked up in the same file system, relative to the level file, the asset search paths aren't used.
+func (dl DataLoader) LoadLevelDataFS(fsys fs.FS, name string) (LevelData, error) {
+	loadedData := LevelData{}
+
+	dataFileContent, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return loadedData, err
+	}
+
+	fsLoader := DataLoader{
+		fsys: fsys,
+	}
+
+	return fsLoader.decodeLevelDataFile(dataFileContent, path.Dir(name))
 }
 
-func (dl DataLoader) decodeLevelDataFile(content []byte) (LevelData, error) {
+// decodeLevelDataFile decodes a level file's content and loads its assets, the level directory is the one holding the
+// level file.
+func (dl DataLoader) decodeLevelDataFile(content []byte, levelDir string) (LevelData, error) {
 	loadedData := LevelData{}
 
 	err := json.Unmarshal(content, &loadedData)
@@ -30,17 +71,41 @@ func (dl DataLoader) decodeLevelDataFile(content []byte) (LevelData, error) {
 		return loadedData, err
 	}
 
-	if len(loadedData.TextureFilenames) > 0 {
-		tl := NewTextureLoader()
+	if errs := ValidateLevelContent(content); len(errs) > 0 {
+		return loadedData, errs
+	}
+
+	// Sprites without a scale are rendered at full size.
+	for i := range loadedData.Sprites {
+		if loadedData.Sprites[i].Scale == 0.0 {
+			loadedData.Sprites[i].Scale = 1.0
+		}
+	}
+
+	// Doors without a speed take a second to open.
+	for i := range loadedData.Doors {
+		if loadedData.Doors[i].Speed == 0.0 {
+			loadedData.Doors[i].Speed = 1.0
+		}
+	}
+
+	if errs := ValidateLevelData(loadedData); len(errs) > 0 {
+		return loadedData, errs
+	}
+
+	dl.resolveAssetPaths(&loadedData, levelDir)
+
+	if len(loadedData.TextureSources) > 0 {
+		tl := dl.newTextureLoader()
 
-		loadedData.Textures, err = tl.LoadTextureData(loadedData.TextureFilenames)
+		loadedData.Textures, err = tl.LoadTextureSources(loadedData.TextureSources)
 		if err != nil {
 			return loadedData, err
 		}
 
 	}
 	if loadedData.SkyTextureFilename != "" {
-		tl := NewTextureLoader()
+		tl := dl.newTextureLoader()
 
 		skyTexture, err := tl.LoadTextureData([]string{loadedData.SkyTextureFilename})
 		if err != nil {
@@ -55,3 +120,64 @@ func (dl DataLoader) decodeLevelDataFile(content []byte) (LevelData, error) {
 
 	return loadedData, nil
 }
+
+func (dl DataLoader) newTextureLoader() TextureLoader {
+	if dl.fsys == nil {
+		return NewTextureLoader()
+	}
+
+	return NewTextureLoaderFS(dl.fsys)
+}
+
+// resolveAssetPaths replaces the texture and sky paths of the level with the paths of the files they resolve to.
+func (dl DataLoader) resolveAssetPaths(ld *LevelData, levelDir string) {
+	for i := range ld.TextureSources {
+		source := &ld.TextureSources[i]
+		if source.Filename != "" {
+			source.Filename = dl.resolveAssetPath(source.Filename, levelDir)
+		}
+
+		if source.Strip != "" {
+			source.Strip = dl.resolveAssetPath(source.Strip, levelDir)
+		}
+
+		for j := range source.Frames {
+			source.Frames[j] = dl.resolveAssetPath(source.Frames[j], levelDir)
+		}
+	}
+
+	if ld.SkyTextureFilename != "" {
+		ld.SkyTextureFilename = dl.resolveAssetPath(ld.SkyTextureFilename, levelDir)
+	}
+}
+
+// resolveAssetPath looks for an asset in the level directory, then in the search paths, then in the working directory
+// like older levels expect. Absolute paths are kept as is. Assets that can't be found resolve to the level directory,
+// so loading them reports the path expected first.
+func (dl DataLoader) resolveAssetPath(assetPath, levelDir string) string {
+	if dl.fsys != nil {
+		return path.Join(levelDir, assetPath)
+	}
+
+	if filepath.IsAbs(assetPath) {
+		return assetPath
+	}
+
+	levelPath := filepath.Join(levelDir, assetPath)
+	if _, err := os.Stat(levelPath); err == nil {
+		return levelPath
+	}
+
+	for _, searchPath := range dl.assetSearchPaths {
+		searchedPath := filepath.Join(searchPath, assetPath)
+		if _, err := os.Stat(searchedPath); err == nil {
+			return searchedPath
+		}
+	}
+
+	if _, err := os.Stat(assetPath); err == nil {
+		return assetPath
+	}
+
+	return levelPath
+}
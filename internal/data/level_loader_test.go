@@ -1,8 +1,11 @@
 package data
 
 import (
-	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
@@ -23,11 +26,21 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 					{1, 0, 1},
 					{1, 1, 1},
 				},
-				TextureFilenames: []string{},
-				AmbientLight:     0.5,
+				TextureSources: []TextureSourceData{},
+				FloorTextures: [][]int{
+					{0, 0, 0},
+					{0, 1, 0},
+					{0, 0, 0},
+				},
+				CeilingTextures: [][]int{
+					{0, 0, 0},
+					{0, 2, 0},
+					{0, 0, 0},
+				},
+				AmbientLight: 0.5,
 				PlayerCoordData: PlayerCoordData{
-					PlayerX:     1.0,
-					PlayerY:     1.0,
+					PlayerX:     1.5,
+					PlayerY:     1.5,
 					PlayerAngle: 45.0,
 				},
 			},
@@ -40,11 +53,20 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 					[1, 0, 1],
 					[1, 1, 1]
 				],
-				"textureFilenames": [],
 				"textures": [],
+				"floorTextures": [
+					[0, 0, 0],
+					[0, 1, 0],
+					[0, 0, 0]
+				],
+				"ceilingTextures": [
+					[0, 0, 0],
+					[0, 2, 0],
+					[0, 0, 0]
+				],
 				"ambientLight": 0.5,
-				"playerX": 1.0,
-				"playerY": 1.0,
+				"playerX": 1.5,
+				"playerY": 1.5,
 				"playerAngle": 45.0
 			}`),
 			err: false,
@@ -60,8 +82,8 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 					{1, 0, 1},
 					{1, 1, 1},
 				},
-				TextureFilenames: []string{
-					"../../assets/test/test-black-pixel.png",
+				TextureSources: []TextureSourceData{
+					{Filename: "../../assets/test/test-black-pixel.png"},
 				},
 				Textures: []TextureData{
 					{
@@ -79,8 +101,8 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 					Data:   []uint8{0x00, 0x00, 0x00, 0xFF},
 				},
 				PlayerCoordData: PlayerCoordData{
-					PlayerX:     1.0,
-					PlayerY:     1.0,
+					PlayerX:     1.5,
+					PlayerY:     1.5,
 					PlayerAngle: 45.0,
 				},
 			},
@@ -97,19 +119,160 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 					"../../assets/test/test-black-pixel.png"
 				],
 				"skyTexture": "../../assets/test/test-black-pixel.png",
-				"playerX": 1.0,
-				"playerY": 1.0,
+				"playerX": 1.5,
+				"playerY": 1.5,
 				"playerAngle": 45.0
 			}`),
 			err: false,
 		},
+		{
+			name: "animated_texture_data",
+			expected: LevelData{
+				Name:   "test_data",
+				Width:  3,
+				Height: 3,
+				Map: [][]int{
+					{1, 1, 1},
+					{1, 0, 1},
+					{1, 1, 1},
+				},
+				TextureSources: []TextureSourceData{
+					{Strip: "../../assets/test/test-strip-small.png", FrameCount: 2, FrameRate: 4.0},
+				},
+				Textures: []TextureData{
+					{
+						Name:   "../../assets/test/test-strip-small.png",
+						Width:  2,
+						Height: 2,
+						Data: []uint8{
+							0x00, 0x00, 0x00, 0xFF,
+							0xFF, 0xFF, 0xFF, 0xFF,
+							0x00, 0x00, 0x00, 0xFF,
+							0xFF, 0xFF, 0xFF, 0xFF,
+							0x00, 0x00, 0x00, 0xFF,
+							0x00, 0x00, 0x00, 0xFF,
+							0xFF, 0xFF, 0xFF, 0xFF,
+							0xFF, 0xFF, 0xFF, 0xFF,
+						},
+						FrameCount: 2,
+						FrameRate:  4.0,
+					},
+				},
+				PlayerCoordData: PlayerCoordData{
+					PlayerX: 1.5,
+					PlayerY: 1.5,
+				},
+			},
+			data: []byte(`{
+				"name": "test_data",
+				"width": 3,
+				"height": 3,
+				"map": [
+					[1, 1, 1],
+					[1, 0, 1],
+					[1, 1, 1]
+				],
+				"textures": [
+					{"strip": "../../assets/test/test-strip-small.png", "frameCount": 2, "frameRate": 4.0}
+				],
+				"playerX": 1.5,
+				"playerY": 1.5
+			}`),
+			err: false,
+		},
+		{
+			name: "sprite_data",
+			expected: LevelData{
+				Name:   "test_data",
+				Width:  4,
+				Height: 3,
+				Map: [][]int{
+					{1, 1, 1, 1},
+					{1, 0, 0, 1},
+					{1, 1, 1, 1},
+				},
+				Sprites: []SpriteData{
+					{
+						X:         1.5,
+						Y:         1.5,
+						TextureId: 1,
+						Scale:     1.0,
+					},
+					{
+						X:              2.5,
+						Y:              1.5,
+						TextureId:      2,
+						Scale:          0.5,
+						VerticalOffset: 0.25,
+					},
+				},
+				PlayerCoordData: PlayerCoordData{
+					PlayerX: 1.5,
+					PlayerY: 1.5,
+				},
+			},
+			data: []byte(`{
+				"name": "test_data",
+				"width": 4,
+				"height": 3,
+				"map": [
+					[1, 1, 1, 1],
+					[1, 0, 0, 1],
+					[1, 1, 1, 1]
+				],
+				"playerX": 1.5,
+				"playerY": 1.5,
+				"sprites": [
+					{"x": 1.5, "y": 1.5, "texture": 1},
+					{"x": 2.5, "y": 1.5, "texture": 2, "scale": 0.5, "verticalOffset": 0.25}
+				]
+			}`),
+			err: false,
+		},
+		{
+			name: "partial_wall_shading",
+			expected: LevelData{
+				Name:   "test_data",
+				Width:  3,
+				Height: 3,
+				Map: [][]int{
+					{1, 1, 1},
+					{1, 0, 1},
+					{1, 1, 1},
+				},
+				WallShading: WallShadingData{
+					North: 0.5,
+					South: 1.0,
+					East:  0.75,
+					West:  1.0,
+				},
+				PlayerCoordData: PlayerCoordData{
+					PlayerX: 1.5,
+					PlayerY: 1.5,
+				},
+			},
+			data: []byte(`{
+				"name": "test_data",
+				"width": 3,
+				"height": 3,
+				"map": [
+					[1, 1, 1],
+					[1, 0, 1],
+					[1, 1, 1]
+				],
+				"wallShading": {"north": 0.5, "east": 0.75},
+				"playerX": 1.5,
+				"playerY": 1.5
+			}`),
+			err: false,
+		},
 		{
 			name:     "bad_data",
 			expected: LevelData{},
 			data: []byte(`{
 				"data": "bad_data"
 			}`),
-			err: false,
+			err: true,
 		},
 		{
 			name:     "invalid_json",
@@ -123,7 +286,7 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			dataLoader := DataLoader{}
 
-			got, err := dataLoader.decodeLevelDataFile(tc.data)
+			got, err := dataLoader.decodeLevelDataFile(tc.data, "")
 
 			// Failed on error
 			if tc.err && err == nil {
@@ -141,3 +304,120 @@ func Test_DataLoader_DecodeLevelDataFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_DataLoader_ResolveAssetPath(t *testing.T) {
+	root := t.TempDir()
+	levelDir := filepath.Join(root, "level")
+	searchDirs := []string{filepath.Join(root, "first"), filepath.Join(root, "second")}
+
+	for _, filename := range []string{
+		filepath.Join(levelDir, "textures", "level.png"),
+		filepath.Join(searchDirs[0], "textures", "shared.png"),
+		filepath.Join(searchDirs[1], "textures", "shared.png"),
+		filepath.Join(searchDirs[1], "other.png"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(filename, []byte{}, 0644); err != nil {
+			t.Fatalf("Failed to create test asset: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "level_directory",
+			path:     "textures/level.png",
+			expected: filepath.Join(levelDir, "textures", "level.png"),
+		},
+		{
+			name:     "first_search_path",
+			path:     "textures/shared.png",
+			expected: filepath.Join(searchDirs[0], "textures", "shared.png"),
+		},
+		{
+			name:     "second_search_path",
+			path:     "other.png",
+			expected: filepath.Join(searchDirs[1], "other.png"),
+		},
+		// Levels written before assets were resolved next to them use paths from the repository root.
+		{
+			name:     "working_directory",
+			path:     filepath.Join("..", "..", "assets", "test", "test-black-pixel.png"),
+			expected: filepath.Join("..", "..", "assets", "test", "test-black-pixel.png"),
+		},
+		{
+			name:     "missing",
+			path:     "./missing.png",
+			expected: filepath.Join(levelDir, "missing.png"),
+		},
+		{
+			name:     "absolute",
+			path:     filepath.Join(root, "absolute.png"),
+			expected: filepath.Join(root, "absolute.png"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewDataLoader(searchDirs...).resolveAssetPath(tc.path, levelDir)
+
+			if got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// Levels load wherever they're moved to, as long as their assets move along.
+func Test_DataLoader_LoadLevelDataRelativeAssets(t *testing.T) {
+	texture, err := os.ReadFile(filepath.Join("..", "..", "assets", "test", "test-black-pixel.png"))
+	if err != nil {
+		t.Fatalf("Failed to read test texture: %v", err)
+	}
+
+	levelDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(levelDir, "textures"), 0755); err != nil {
+		t.Fatalf("Failed to create texture directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(levelDir, "textures", "black.png"), texture, 0644); err != nil {
+		t.Fatalf("Failed to write test texture: %v", err)
+	}
+
+	level := []byte(`{
+		"name": "test_data",
+		"width": 3,
+		"height": 3,
+		"map": [
+			[1, 1, 1],
+			[1, 0, 1],
+			[1, 1, 1]
+		],
+		"textures": ["textures/black.png"],
+		"skyTexture": "textures/black.png",
+		"playerX": 1.5,
+		"playerY": 1.5
+	}`)
+	levelFilename := filepath.Join(levelDir, "level.json")
+	if err := os.WriteFile(levelFilename, level, 0644); err != nil {
+		t.Fatalf("Failed to write test level: %v", err)
+	}
+
+	got, err := NewDataLoader().LoadLevelData(levelFilename)
+	if err != nil {
+		t.Fatalf("Did not expect error, got %v", err)
+	}
+
+	expected := filepath.Join(levelDir, "textures", "black.png")
+	if len(got.Textures) != 1 || got.Textures[0].Name != expected {
+		t.Errorf("Expected texture %s, got %v", expected, got.Textures)
+	}
+
+	if got.SkyTexture.Name != expected {
+		t.Errorf("Expected sky texture %s, got %s", expected, got.SkyTexture.Name)
+	}
+}
@@ -1,5 +1,9 @@
 package data
 
+import (
+	"encoding/json"
+)
+
 type LevelData struct {
 	Name   string  `json:"name"`
 	Width  int     `json:"width"`
@@ -7,23 +11,169 @@ type LevelData struct {
 	Map    [][]int `json:"map"`
 
 	// Normal wall textures
-	TextureFilenames []string `json:"textures"`
-	Textures         []TextureData
+	TextureSources []TextureSourceData `json:"textures"`
+	Textures       []TextureData
+
+	// Floor and ceiling textures, per map cell. Values index the normal wall textures, 0 means no texture (flat floor
+	//	colour or sky).
+	FloorTextures   [][]int `json:"floorTextures"`
+	CeilingTextures [][]int `json:"ceilingTextures"`
 
 	// Sky texture
 	SkyTextureFilename string `json:"skyTexture"`
 	SkyTexture         TextureData
 
-	AmbientLight float64 `json:"ambientLight"`
+	// Optional wall definitions, giving wall types different textures per face.
+	Walls []WallData `json:"walls"`
+
+	AmbientLight float64         `json:"ambientLight"`
+	Fog          FogData         `json:"fog"`
+	WallShading  WallShadingData `json:"wallShading"`
+
+	Sprites []SpriteData `json:"sprites"`
+	Doors   []DoorData   `json:"doors"`
 
 	PlayerCoordData
+
+	// Manifest of the package the level was loaded from, empty for plain level files.
+	Package PackageManifest `json:"-"`
+}
+
+// WallData defines a wall type with a texture per face. Map cells holding the wall type use these textures, instead of
+// the type being used as the texture id. Faces are named after the direction they're facing, north being towards the
+// top of the map. Faces without a texture use the wall's texture, which defaults to the wall type.
+type WallData struct {
+	Type    int `json:"type"`
+	Texture int `json:"texture"`
+	North   int `json:"north"`
+	South   int `json:"south"`
+	East    int `json:"east"`
+	West    int `json:"west"`
+}
+
+// ResolveFaceTextures returns a copy of the wall definition with the defaults applied to every face.
+func (wd WallData) ResolveFaceTextures() WallData {
+	resolved := wd
+	if resolved.Texture == 0 {
+		resolved.Texture = resolved.Type
+	}
+
+	for _, face := range []*int{&resolved.North, &resolved.South, &resolved.East, &resolved.West} {
+		if *face == 0 {
+			*face = resolved.Texture
+		}
+	}
+
+	return resolved
+}
+
+// Fog modes, an empty mode disables fog.
+const (
+	FOG_NONE        = ""
+	FOG_LINEAR      = "linear"
+	FOG_EXPONENTIAL = "exponential"
+)
+
+// FogData describes distance fog. Linear fog goes from clear at the start distance to fully fogged at the end distance,
+// exponential fog thickens with the density. Distances are in map units, the colour is RGB.
+type FogData struct {
+	Mode    string  `json:"mode"`
+	Color   [3]int  `json:"color"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Density float64 `json:"density"`
+}
+
+// WallShadingData holds brightness multipliers, between 0 and 1, for each face of the walls. Faces are named after the
+// direction they're facing, north being towards the top of the map. Faces missing from a level file are left unshaded,
+// a zero value disables shading altogether.
+type WallShadingData struct {
+	North float64 `json:"north"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	West  float64 `json:"west"`
 }
 
+// UnmarshalJSON decodes the wall shading, faces missing from the JSON object are left unshaded.
+func (wsd *WallShadingData) UnmarshalJSON(content []byte) error {
+	type plainWallShadingData WallShadingData
+	shading := plainWallShadingData{North: 1.0, South: 1.0, East: 1.0, West: 1.0}
+
+	if err := json.Unmarshal(content, &shading); err != nil {
+		return err
+	}
+	*wsd = WallShadingData(shading)
+
+	return nil
+}
+
+// TextureSourceData is an entry of the level's texture list. Static textures are given as a single filename. Animated
+// textures are given as an object listing either one file per frame, or a strip file holding the frames side by side,
+// along with a frame rate in frames per second.
+type TextureSourceData struct {
+	Filename   string   `json:"-"`
+	Frames     []string `json:"frames"`
+	Strip      string   `json:"strip"`
+	FrameCount int      `json:"frameCount"`
+	FrameRate  float64  `json:"frameRate"`
+}
+
+// UnmarshalJSON decodes a texture list entry, either a filename or an animated texture object.
+func (tsd *TextureSourceData) UnmarshalJSON(content []byte) error {
+	filename := ""
+	if err := json.Unmarshal(content, &filename); err == nil {
+		*tsd = TextureSourceData{Filename: filename}
+		return nil
+	}
+
+	type plainTextureSourceData TextureSourceData
+	source := plainTextureSourceData{}
+	if err := json.Unmarshal(content, &source); err != nil {
+		return err
+	}
+	*tsd = TextureSourceData(source)
+
+	return nil
+}
+
+// TextureData holds RGBA texture data. Animated textures hold their frames one after the other, the width and height
+// are those of a single frame. Static textures have a frame count of 0.
 type TextureData struct {
-	Name   string
-	Width  int
-	Height int
-	Data   []uint8
+	Name       string
+	Width      int
+	Height     int
+	Data       []uint8
+	FrameCount int
+	FrameRate  float64
+}
+
+// SpriteData describes a billboard sprite placed in the world. The texture id indexes the normal wall textures, the
+// same way map values do. Sprites stand on the floor, the vertical offset lifts them up in world units.
+type SpriteData struct {
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+	TextureId      int     `json:"texture"`
+	Scale          float64 `json:"scale"`
+	VerticalOffset float64 `json:"verticalOffset"`
+}
+
+// DoorData describes a sliding door occupying a map cell. The door is recessed half a cell and slides open along its
+// plane. The speed is in doors per second, the auto close delay is in seconds and 0 disables auto closing.
+type DoorData struct {
+	X              int     `json:"x"`
+	Y              int     `json:"y"`
+	TextureId      int     `json:"texture"`
+	Speed          float64 `json:"speed"`
+	AutoCloseDelay float64 `json:"autoCloseDelay"`
+}
+
+// DoorState is the state of a door at a given time, as seen by the renderer. Horizontal doors span along the X axis.
+type DoorState struct {
+	X            int
+	Y            int
+	TextureId    int
+	Horizontal   bool
+	OpenFraction float64
 }
 
 type PlayerCoordData struct {
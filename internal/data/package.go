@@ -0,0 +1,73 @@
+package data
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+const (
+	PACKAGE_EXTENSION  = ".zip"
+	PACKAGE_MANIFEST   = "manifest.json"
+	PACKAGE_LEVEL_FILE = "level.json"
+)
+
+// PackageManifest describes a level package, a zip archive or a directory holding a level file along with its assets.
+// The level path is relative to the package root and defaults to level.json.
+type PackageManifest struct {
+	Name        string `json:"name"`
+	Author      string `json:"author"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Level       string `json:"level"`
+}
+
+// LoadPackage loads the level of a zip level package. The level's assets are all loaded from the package.
+func (dl DataLoader) LoadPackage(filename string) (LevelData, error) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return LevelData{}, err
+	}
+	defer reader.Close()
+
+	return dl.LoadPackageFS(&reader.Reader)
+}
+
+// LoadPackageFS loads the level of a level package from its file system, rooted at the package root.
+func (dl DataLoader) LoadPackageFS(fsys fs.FS) (LevelData, error) {
+	manifest, err := dl.loadPackageManifest(fsys)
+	if err != nil {
+		return LevelData{}, err
+	}
+
+	loadedData, err := dl.LoadLevelDataFS(fsys, manifest.Level)
+	loadedData.Package = manifest
+
+	return loadedData, err
+}
+
+func (dl DataLoader) loadPackageManifest(fsys fs.FS) (PackageManifest, error) {
+	content, err := fs.ReadFile(fsys, PACKAGE_MANIFEST)
+	if err != nil {
+		return PackageManifest{}, fmt.Errorf("Failed to read package manifest: %w", err)
+	}
+
+	manifest := PackageManifest{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return PackageManifest{}, fmt.Errorf("Failed to decode package manifest: %w", err)
+	}
+
+	if manifest.Level == "" {
+		manifest.Level = PACKAGE_LEVEL_FILE
+	}
+
+	if !fs.ValidPath(manifest.Level) {
+		return PackageManifest{}, fmt.Errorf("Package level %q is not a path within the package", manifest.Level)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rebay1982/redcaster/assets"
+)
+
+const testPackageLevel = `{
+	"name": "packaged",
+	"width": 3,
+	"height": 3,
+	"map": [
+		[1, 1, 1],
+		[1, 0, 1],
+		[1, 1, 1]
+	],
+	"textures": ["../textures/black.png"],
+	"playerX": 1.5,
+	"playerY": 1.5
+}`
+
+// newTestPackage returns the files of a level package, the level in a sub directory referencing a shared texture.
+func newTestPackage(t *testing.T) fstest.MapFS {
+	texture, err := os.ReadFile(filepath.Join("..", "..", "assets", "test", "test-black-pixel.png"))
+	if err != nil {
+		t.Fatalf("Failed to read test texture: %v", err)
+	}
+
+	return fstest.MapFS{
+		"manifest.json":      {Data: []byte(`{"name": "Test package", "author": "Tester", "level": "levels/one.json"}`)},
+		"levels/one.json":    {Data: []byte(testPackageLevel)},
+		"textures/black.png": {Data: texture},
+	}
+}
+
+func Test_DataLoader_LoadPackageFS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(fsys fstest.MapFS)
+		expected PackageManifest
+		err      bool
+	}{
+		{
+			name:     "valid",
+			modify:   func(fsys fstest.MapFS) {},
+			expected: PackageManifest{Name: "Test package", Author: "Tester", Level: "levels/one.json"},
+		},
+		{
+			name: "default_level_file",
+			modify: func(fsys fstest.MapFS) {
+				fsys["manifest.json"] = &fstest.MapFile{Data: []byte(`{"name": "Test package"}`)}
+				fsys["level.json"] = &fstest.MapFile{Data: []byte(`{"name": "packaged", "width": 3, "height": 3,
+					"map": [[1, 1, 1], [1, 0, 1], [1, 1, 1]], "textures": ["textures/black.png"],
+					"playerX": 1.5, "playerY": 1.5}`)}
+			},
+			expected: PackageManifest{Name: "Test package", Level: "level.json"},
+		},
+		{
+			name: "missing_manifest",
+			modify: func(fsys fstest.MapFS) {
+				delete(fsys, "manifest.json")
+			},
+			err: true,
+		},
+		{
+			name: "unknown_manifest_field",
+			modify: func(fsys fstest.MapFS) {
+				fsys["manifest.json"] = &fstest.MapFile{Data: []byte(`{"name": "Test package", "levle": "one.json"}`)}
+			},
+			err: true,
+		},
+		{
+			name: "level_outside_package",
+			modify: func(fsys fstest.MapFS) {
+				fsys["manifest.json"] = &fstest.MapFile{Data: []byte(`{"level": "../one.json"}`)}
+			},
+			err: true,
+		},
+		{
+			name: "missing_texture",
+			modify: func(fsys fstest.MapFS) {
+				delete(fsys, "textures/black.png")
+			},
+			expected: PackageManifest{Name: "Test package", Author: "Tester", Level: "levels/one.json"},
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := newTestPackage(t)
+			tc.modify(fsys)
+
+			got, err := NewDataLoader().LoadPackageFS(fsys)
+
+			if tc.err && err == nil {
+				t.Errorf("Expected err, got %v", err)
+			}
+
+			if !tc.err && err != nil {
+				t.Errorf("Did not expect error, got %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got.Package); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+
+			if !tc.err && (len(got.Textures) != 1 || got.Textures[0].Name != "textures/black.png") {
+				t.Errorf("Expected the package texture textures/black.png, got %v", got.Textures)
+			}
+		})
+	}
+}
+
+// Packages load the same from a zip archive and from a directory.
+func Test_DataLoader_LoadLevelDataPackage(t *testing.T) {
+	packageFiles := newTestPackage(t)
+	root := t.TempDir()
+
+	archiveName := filepath.Join(root, "test.zip")
+	archive, err := os.Create(archiveName)
+	if err != nil {
+		t.Fatalf("Failed to create package archive: %v", err)
+	}
+	writer := zip.NewWriter(archive)
+
+	directory := filepath.Join(root, "test")
+	for name, file := range packageFiles {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s to the package archive: %v", name, err)
+		}
+		entry.Write(file.Data)
+
+		filename := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create package directory: %v", err)
+		}
+		if err := os.WriteFile(filename, file.Data, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write package archive: %v", err)
+	}
+	archive.Close()
+
+	for _, filename := range []string{archiveName, directory} {
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			got, err := NewDataLoader().LoadLevelData(filename)
+			if err != nil {
+				t.Fatalf("Did not expect error, got %v", err)
+			}
+
+			if got.Name != "packaged" || got.Package.Name != "Test package" {
+				t.Errorf("Expected the packaged level, got level %q from package %q", got.Name, got.Package.Name)
+			}
+
+			if len(got.Textures) != 1 || got.Textures[0].Width != 1 {
+				t.Errorf("Expected the 1x1 package texture, got %v", got.Textures)
+			}
+		})
+	}
+}
+
+// The demo level is embedded in the binary along with its textures.
+func Test_DataLoader_LoadEmbeddedDemo(t *testing.T) {
+	got, err := NewDataLoader().LoadLevelDataFS(assets.Demo, "demo/demo.json")
+	if err != nil {
+		t.Fatalf("Did not expect error, got %v", err)
+	}
+
+	if len(got.Textures) == 0 || got.SkyTexture.Data == nil {
+		t.Errorf("Expected the demo textures to be loaded")
+	}
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"time"
+)
+
+type doorKey struct {
+	x int
+	y int
+}
+
+// MapMode selects the map overlay drawn over the view.
+type MapMode int
+
+const (
+	MAP_MODE_OFF MapMode = iota
+	// Small map of the surroundings in a corner of the screen.
+	MAP_MODE_MINIMAP
+	// Full screen map of the parts of the level seen so far.
+	MAP_MODE_AUTOMAP
+
+	mapModeCount
+)
+
+var mapModeNames = [mapModeCount]string{
+	MAP_MODE_OFF:     "off",
+	MAP_MODE_MINIMAP: "minimap",
+	MAP_MODE_AUTOMAP: "automap",
+}
+
+func (m MapMode) String() string {
+	if m < 0 || m >= mapModeCount {
+		return "unknown"
+	}
+
+	return mapModeNames[m]
+}
+
+// Next returns the mode following this one, wrapping around to the map being off.
+func (m MapMode) Next() MapMode {
+	return (m + 1) % mapModeCount
+}
+
+// ParseMapMode returns the map mode with the given name.
+func ParseMapMode(name string) (MapMode, bool) {
+	for mode, modeName := range mapModeNames {
+		if modeName == name {
+			return MapMode(mode), true
+		}
+	}
+
+	return MAP_MODE_OFF, false
+}
+
+// WorldSnapshot is an immutable view of the world at a given game tick. Snapshots are published by the game and
+// consumed by the renderer, which can't safely read the game's state while it's being updated.
+type WorldSnapshot struct {
+	Tick uint64
+	Time time.Time
+	// Simulated time since the game started, the game clock.
+	Elapsed time.Duration
+	Player  PlayerCoordData
+	Doors   []DoorState
+	Sprites []SpriteData
+	MapMode MapMode
+
+	doorIndex map[doorKey]int
+}
+
+// NewWorldSnapshot creates a snapshot. The doors and sprites slices must not be modified afterwards.
+func NewWorldSnapshot(tick uint64, time time.Time, elapsed time.Duration, player PlayerCoordData, doors []DoorState,
+	sprites []SpriteData) WorldSnapshot {
+	doorIndex := make(map[doorKey]int, len(doors))
+	for i, door := range doors {
+		doorIndex[doorKey{x: door.X, y: door.Y}] = i
+	}
+
+	return WorldSnapshot{
+		Tick:      tick,
+		Time:      time,
+		Elapsed:   elapsed,
+		Player:    player,
+		Doors:     doors,
+		Sprites:   sprites,
+		doorIndex: doorIndex,
+	}
+}
+
+// GetDoorState returns the state of the door at the given coordinates, if there's one.
+func (ws WorldSnapshot) GetDoorState(x, y float64) (DoorState, bool) {
+	if x < 0.0 || y < 0.0 {
+		return DoorState{}, false
+	}
+
+	i, ok := ws.doorIndex[doorKey{x: int(x), y: int(y)}]
+	if !ok {
+		return DoorState{}, false
+	}
+
+	return ws.Doors[i], true
+}
+
+// InterpolateWorldSnapshots blends two consecutive snapshots, alpha being 0 for the previous snapshot and 1 for the
+// current one. The player and door positions are interpolated, everything else is taken from the current snapshot.
+func InterpolateWorldSnapshots(previous, current WorldSnapshot, alpha float64) WorldSnapshot {
+	if alpha >= 1.0 || len(previous.Doors) != len(current.Doors) {
+		return current
+	}
+
+	lerp := func(a, b float64) float64 {
+		return a + (b-a)*alpha
+	}
+
+	// Turn the shortest way around when the angle wraps between 0 and 360.
+	angleDelta := current.Player.PlayerAngle - previous.Player.PlayerAngle
+	if angleDelta > 180.0 {
+		angleDelta -= 360.0
+	} else if angleDelta < -180.0 {
+		angleDelta += 360.0
+	}
+
+	angle := previous.Player.PlayerAngle + angleDelta*alpha
+	if angle < 0.0 {
+		angle += 360.0
+	} else if angle >= 360.0 {
+		angle -= 360.0
+	}
+
+	player := PlayerCoordData{
+		PlayerX:     lerp(previous.Player.PlayerX, current.Player.PlayerX),
+		PlayerY:     lerp(previous.Player.PlayerY, current.Player.PlayerY),
+		PlayerAngle: angle,
+	}
+
+	doors := make([]DoorState, len(current.Doors))
+	for i, door := range current.Doors {
+		doors[i] = door
+		doors[i].OpenFraction = lerp(previous.Doors[i].OpenFraction, door.OpenFraction)
+	}
+
+	interpolated := current
+	interpolated.Player = player
+	interpolated.Doors = doors
+
+	return interpolated
+}
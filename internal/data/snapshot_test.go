@@ -0,0 +1,128 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_WorldSnapshot_GetDoorState(t *testing.T) {
+	snapshot := NewWorldSnapshot(1, time.Time{}, 0, PlayerCoordData{}, []DoorState{
+		{X: 2, Y: 3, TextureId: 1},
+		{X: 4, Y: 1, TextureId: 2, OpenFraction: 0.5},
+	}, nil)
+
+	testCases := []struct {
+		name     string
+		x, y     float64
+		want     DoorState
+		wantDoor bool
+	}{
+		{
+			name:     "first_door",
+			x:        2.5,
+			y:        3.1,
+			want:     DoorState{X: 2, Y: 3, TextureId: 1},
+			wantDoor: true,
+		},
+		{
+			name:     "second_door",
+			x:        4.0,
+			y:        1.9,
+			want:     DoorState{X: 4, Y: 1, TextureId: 2, OpenFraction: 0.5},
+			wantDoor: true,
+		},
+		{
+			name:     "no_door",
+			x:        1.0,
+			y:        1.0,
+			want:     DoorState{},
+			wantDoor: false,
+		},
+		{
+			name:     "negative_coordinates",
+			x:        -0.5,
+			y:        3.0,
+			want:     DoorState{},
+			wantDoor: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := snapshot.GetDoorState(tc.x, tc.y)
+
+			if ok != tc.wantDoor {
+				t.Errorf("Expected door %t, got %t", tc.wantDoor, ok)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Failed to validate door state: -want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InterpolateWorldSnapshots(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous WorldSnapshot
+		current  WorldSnapshot
+		alpha    float64
+		want     WorldSnapshot
+	}{
+		{
+			name: "halfway",
+			previous: NewWorldSnapshot(1, time.Time{}, 0, PlayerCoordData{PlayerX: 1.0, PlayerY: 2.0, PlayerAngle: 10.0},
+				[]DoorState{{X: 1, Y: 1, OpenFraction: 0.0}}, nil),
+			current: NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerX: 2.0, PlayerY: 1.0, PlayerAngle: 20.0},
+				[]DoorState{{X: 1, Y: 1, OpenFraction: 0.5}}, nil),
+			alpha: 0.5,
+			want: NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerX: 1.5, PlayerY: 1.5, PlayerAngle: 15.0},
+				[]DoorState{{X: 1, Y: 1, OpenFraction: 0.25}}, nil),
+		},
+		{
+			name:     "angle_wraps_around",
+			previous: NewWorldSnapshot(1, time.Time{}, 0, PlayerCoordData{PlayerAngle: 350.0}, nil, nil),
+			current:  NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerAngle: 10.0}, nil, nil),
+			alpha:    0.75,
+			want:     NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerAngle: 5.0}, []DoorState{}, nil),
+		},
+		{
+			name:     "alpha_past_current",
+			previous: NewWorldSnapshot(1, time.Time{}, 0, PlayerCoordData{PlayerX: 1.0}, nil, nil),
+			current:  NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerX: 2.0}, nil, nil),
+			alpha:    1.5,
+			want:     NewWorldSnapshot(2, time.Time{}, 0, PlayerCoordData{PlayerX: 2.0}, nil, nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := InterpolateWorldSnapshots(tc.previous, tc.current, tc.alpha)
+
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(WorldSnapshot{}), cmpopts.EquateApprox(0, 0.000001)); diff != "" {
+				t.Errorf("Failed to validate snapshot: -want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_MapMode(t *testing.T) {
+	for mode := MAP_MODE_OFF; mode < mapModeCount; mode++ {
+		parsed, ok := ParseMapMode(mode.String())
+		if !ok || parsed != mode {
+			t.Errorf("Expected %v to parse back to itself, got %v", mode, parsed)
+		}
+	}
+
+	if _, ok := ParseMapMode("radar"); ok {
+		t.Errorf("Expected an unknown map mode not to parse")
+	}
+
+	if got := MAP_MODE_AUTOMAP.Next(); got != MAP_MODE_OFF {
+		t.Errorf("Expected the automap to cycle back to off, got %v", got)
+	}
+}
@@ -1,55 +1,215 @@
 package data
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"image"
-	"image/png"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
 	"os"
+
+	_ "golang.org/x/image/bmp"
 )
 
-type TextureLoader struct{}
+// Largest width or height of a texture, far beyond any real texture. Decoders allocate the whole image up front, a
+// corrupt header claiming billions of pixels must be rejected before decoding.
+const maxTextureDimension = 1 << 15
+
+// TextureLoader loads textures from the OS file system, or from a given file system such as a level package.
+type TextureLoader struct {
+	fsys fs.FS
+}
 
 func NewTextureLoader() TextureLoader {
 	return TextureLoader{}
 }
 
+// NewTextureLoaderFS creates a texture loader reading textures from the given file system, texture filenames are
+// slash-separated paths within it.
+func NewTextureLoaderFS(fsys fs.FS) TextureLoader {
+	return TextureLoader{
+		fsys: fsys,
+	}
+}
+
 func (tl TextureLoader) LoadTextureData(filenames []string) ([]TextureData, error) {
 	textureData := []TextureData{}
 
 	for _, filename := range filenames {
-		file, err := os.Open(filename)
+		texture, err := tl.loadTexture(filename)
 		if err != nil {
 			return textureData, err
 		}
-		defer file.Close()
 
-		img, err := png.Decode(file)
-		if err != nil {
-			return textureData, err
-		}
+		textureData = append(textureData, texture)
+	}
+
+	return textureData, nil
+}
+
+// LoadTextureSources loads the textures of a level's texture list, animated textures included.
+func (tl TextureLoader) LoadTextureSources(sources []TextureSourceData) ([]TextureData, error) {
+	textureData := []TextureData{}
 
-		imgInfo := img.Bounds()
-		rawTextureData, err := tl.getRawTextureData(img)
+	for _, source := range sources {
+		var texture TextureData
+		var err error
+
+		switch {
+		case source.Filename != "":
+			texture, err = tl.loadTexture(source.Filename)
+		case source.Strip != "":
+			texture, err = tl.loadStripTexture(source.Strip, source.FrameCount)
+		default:
+			texture, err = tl.loadFramesTexture(source.Frames)
+		}
 		if err != nil {
 			return textureData, err
 		}
 
-		textureData = append(textureData, TextureData{
-			Name:   filename,
-			Width:  imgInfo.Max.X,
-			Height: imgInfo.Max.Y,
-			Data:   rawTextureData,
-		})
+		if source.Filename == "" {
+			texture.FrameRate = source.FrameRate
+		}
+		textureData = append(textureData, texture)
 	}
 
 	return textureData, nil
 }
 
-func (tl TextureLoader) getRawTextureData(img image.Image) ([]byte, error) {
+func (tl TextureLoader) loadTexture(filename string) (TextureData, error) {
+	file, err := tl.open(filename)
+	if err != nil {
+		return TextureData{}, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return TextureData{}, fmt.Errorf("Failed to read texture %s: %w", filename, err)
+	}
+
+	// PNG, GIF, JPEG and BMP files are supported, the format is detected from the content.
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return TextureData{}, fmt.Errorf("Failed to decode texture %s: %w", filename, err)
+	}
+	if config.Width > maxTextureDimension || config.Height > maxTextureDimension {
+		return TextureData{}, fmt.Errorf("Texture %s is %dx%d, textures can't be larger than %dx%d", filename,
+			config.Width, config.Height, maxTextureDimension, maxTextureDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return TextureData{}, fmt.Errorf("Failed to decode texture %s: %w", filename, err)
+	}
+
+	// The BMP decoder doesn't check colour indexes against the palette, converting such an image would panic.
+	if paletted, ok := img.(*image.Paletted); ok {
+		for _, index := range paletted.Pix {
+			if int(index) >= len(paletted.Palette) {
+				return TextureData{}, fmt.Errorf("Failed to decode texture %s: colour index %d is out of the %d "+
+					"colour palette", filename, index, len(paletted.Palette))
+			}
+		}
+	}
+
+	imgInfo := img.Bounds()
+	return TextureData{
+		Name:   filename,
+		Width:  imgInfo.Dx(),
+		Height: imgInfo.Dy(),
+		Data:   tl.getRawTextureData(img),
+	}, nil
+}
+
+func (tl TextureLoader) open(filename string) (io.ReadCloser, error) {
+	if tl.fsys == nil {
+		return os.Open(filename)
+	}
+
+	return tl.fsys.Open(filename)
+}
+
+// loadFramesTexture loads an animated texture from one file per frame, the frames must all be the same size.
+func (tl TextureLoader) loadFramesTexture(filenames []string) (TextureData, error) {
+	if len(filenames) == 0 {
+		return TextureData{}, errors.New("Animated texture has no frames")
+	}
+
+	frames, err := tl.LoadTextureData(filenames)
+	if err != nil {
+		return TextureData{}, err
+	}
+
+	texture := TextureData{
+		Name:       filenames[0],
+		Width:      frames[0].Width,
+		Height:     frames[0].Height,
+		Data:       make([]uint8, 0, len(frames[0].Data)*len(frames)),
+		FrameCount: len(frames),
+	}
+
+	for _, frame := range frames {
+		if frame.Width != texture.Width || frame.Height != texture.Height {
+			return TextureData{}, fmt.Errorf("Frame %s is %dx%d, expected %dx%d like the first frame", frame.Name,
+				frame.Width, frame.Height, texture.Width, texture.Height)
+		}
+
+		texture.Data = append(texture.Data, frame.Data...)
+	}
+
+	return texture, nil
+}
+
+// loadStripTexture loads an animated texture from a single file holding the frames side by side. The frames are
+// rearranged one after the other, so a frame is laid out like a static texture.
+func (tl TextureLoader) loadStripTexture(filename string, frameCount int) (TextureData, error) {
+	strip, err := tl.loadTexture(filename)
+	if err != nil {
+		return TextureData{}, err
+	}
+
+	if frameCount <= 0 || strip.Width%frameCount != 0 {
+		return TextureData{}, fmt.Errorf("Strip %s is %d pixels wide, which can't be split in %d frames", filename,
+			strip.Width, frameCount)
+	}
+
+	frameWidth := strip.Width / frameCount
+	rowSize := frameWidth << 2
+	texture := TextureData{
+		Name:       filename,
+		Width:      frameWidth,
+		Height:     strip.Height,
+		Data:       make([]uint8, 0, len(strip.Data)),
+		FrameCount: frameCount,
+	}
+
+	for frame := 0; frame < frameCount; frame++ {
+		for y := 0; y < strip.Height; y++ {
+			start := (y*strip.Width + frame*frameWidth) << 2
+			texture.Data = append(texture.Data, strip.Data[start:start+rowSize]...)
+		}
+	}
+
+	return texture, nil
+}
+
+// getRawTextureData returns the image's pixels as tightly packed RGBA rows. Images in any other colour model, paletted,
+// grayscale or non premultiplied for instance, are converted.
+func (tl TextureLoader) getRawTextureData(img image.Image) []byte {
+	bounds := img.Bounds()
 	rgbaImg, ok := img.(*image.RGBA)
-	if !ok {
-		return nil, errors.New("Texture format is not RGBA")
+	if ok && bounds.Min == (image.Point{}) && rgbaImg.Stride == bounds.Dx()<<2 {
+		return rgbaImg.Pix
 	}
 
-	return rgbaImg.Pix, nil
+	rgbaImg = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgbaImg, rgbaImg.Bounds(), img, bounds.Min, draw.Src)
+
+	return rgbaImg.Pix
 }
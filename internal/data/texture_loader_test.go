@@ -75,7 +75,7 @@ func TestTextureLoader_GetTextureData(t *testing.T) {
 		{
 			name: "GetTextureData_bad_format",
 			filenames: []string{
-				"../../assets/test/test-bad-format.png",
+				"../../assets/test/test-not-an-image.png",
 			},
 			want:    []TextureData{},
 			wantErr: true,
@@ -100,3 +100,153 @@ func TestTextureLoader_GetTextureData(t *testing.T) {
 		})
 	}
 }
+
+// Whatever the file format or colour model, textures end up in the same RGBA layout.
+func TestTextureLoader_ImageFormats(t *testing.T) {
+	tl := NewTextureLoader()
+
+	verticalData := []uint8{
+		0x00, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{name: "png_nrgba", filename: "../../assets/test/test-vertical-small-nrgba.png"},
+		{name: "png_paletted", filename: "../../assets/test/test-vertical-small-paletted.png"},
+		{name: "png_gray", filename: "../../assets/test/test-vertical-small-gray.png"},
+		{name: "gif", filename: "../../assets/test/test-vertical-small.gif"},
+		{name: "jpeg", filename: "../../assets/test/test-vertical-small.jpg"},
+		{name: "bmp_24_bits", filename: "../../assets/test/test-vertical-small.bmp"},
+		{name: "bmp_paletted", filename: "../../assets/test/test-vertical-small-paletted.bmp"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tl.LoadTextureData([]string{tc.filename})
+			if err != nil {
+				t.Fatalf("Not expecting error, got %v", err)
+			}
+
+			want := []TextureData{{Name: tc.filename, Width: 2, Height: 2, Data: verticalData}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Failed to validate return value: -want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTextureLoader_LoadTextureSources(t *testing.T) {
+	tl := NewTextureLoader()
+
+	// Vertical stripes then horizontal stripes, one 2x2 frame after the other.
+	animatedData := []uint8{
+		0x00, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0xFF,
+		0x00, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+	}
+
+	tests := []struct {
+		name    string
+		sources []TextureSourceData
+		want    []TextureData
+		wantErr bool
+	}{
+		{
+			name: "LoadTextureSources_frames",
+			sources: []TextureSourceData{
+				{
+					Frames: []string{
+						"../../assets/test/test-vertical-small.png",
+						"../../assets/test/test-horizontal-small.png",
+					},
+					FrameRate: 2.0,
+				},
+			},
+			want: []TextureData{
+				{
+					Name:       "../../assets/test/test-vertical-small.png",
+					Width:      2,
+					Height:     2,
+					Data:       animatedData,
+					FrameCount: 2,
+					FrameRate:  2.0,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "LoadTextureSources_strip",
+			sources: []TextureSourceData{
+				{Filename: "../../assets/test/test-vertical-small.png"},
+				{Strip: "../../assets/test/test-strip-small.png", FrameCount: 2, FrameRate: 4.0},
+			},
+			want: []TextureData{
+				{
+					Name:   "../../assets/test/test-vertical-small.png",
+					Width:  2,
+					Height: 2,
+					Data:   animatedData[:16],
+				},
+				{
+					Name:       "../../assets/test/test-strip-small.png",
+					Width:      2,
+					Height:     2,
+					Data:       animatedData,
+					FrameCount: 2,
+					FrameRate:  4.0,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "LoadTextureSources_bad_strip_frame_count",
+			sources: []TextureSourceData{
+				{Strip: "../../assets/test/test-strip-small.png", FrameCount: 3, FrameRate: 4.0},
+			},
+			want:    []TextureData{},
+			wantErr: true,
+		},
+		{
+			name: "LoadTextureSources_frame_size_mismatch",
+			sources: []TextureSourceData{
+				{
+					Frames: []string{
+						"../../assets/test/test-vertical-small.png",
+						"../../assets/test/test-strip-small.png",
+					},
+					FrameRate: 2.0,
+				},
+			},
+			want:    []TextureData{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tl.LoadTextureSources(tc.sources)
+
+			if tc.wantErr && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+
+			if !tc.wantErr && err != nil {
+				t.Errorf("Not expecting error, got %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Failed to validate return value: -want +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rebay1982/redcaster/internal/config"
+)
+
+// ValidationError describes a single problem found in a level. The field is a path into the level file, like
+// "map[3]" or "sprites[1].texture". Row and column locate the offending map cell and are -1 when not applicable.
+type ValidationError struct {
+	Field  string
+	Row    int
+	Col    int
+	Reason string
+}
+
+func (ve ValidationError) Error() string {
+	if ve.Row >= 0 && ve.Col >= 0 {
+		return fmt.Sprintf("%s (row %d, col %d): %s", ve.Field, ve.Row, ve.Col, ve.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s", ve.Field, ve.Reason)
+}
+
+// ValidationErrors is the list of every problem found in a level, so they can all be fixed in one go.
+type ValidationErrors []ValidationError
+
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, len(ve))
+	for i, err := range ve {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("level has %d validation error(s):\n  %s", len(ve), strings.Join(messages, "\n  "))
+}
+
+func newFieldError(field string, reason string, args ...any) ValidationError {
+	return ValidationError{Field: field, Row: -1, Col: -1, Reason: fmt.Sprintf(reason, args...)}
+}
+
+func newCellError(field string, row, col int, reason string, args ...any) ValidationError {
+	return ValidationError{Field: field, Row: row, Col: col, Reason: fmt.Sprintf(reason, args...)}
+}
+
+// ValidateLevelContent reports fields of a raw level file that don't exist in the level format, usually typos.
+func ValidateLevelContent(content []byte) ValidationErrors {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return ValidationErrors{newFieldError("", "%v", err)}
+	}
+
+	return validateFields("", raw, reflect.TypeOf(LevelData{}))
+}
+
+// validateFields checks the raw object's keys against the struct's JSON tags, descending into structs and lists of
+// structs.
+func validateFields(path string, raw map[string]json.RawMessage, structType reflect.Type) ValidationErrors {
+	errs := ValidationErrors{}
+	fields := jsonFields(structType)
+
+	for _, key := range sortedKeys(raw) {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+
+		fieldType, ok := fields[key]
+		if !ok {
+			reason := "unknown field"
+			if suggestion := closestField(key, fields); suggestion != "" {
+				reason = fmt.Sprintf("unknown field, did you mean %q?", suggestion)
+			}
+			errs = append(errs, newFieldError(fieldPath, "%s", reason))
+			continue
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			element := map[string]json.RawMessage{}
+			if err := json.Unmarshal(raw[key], &element); err == nil {
+				errs = append(errs, validateFields(fieldPath, element, fieldType)...)
+			}
+		}
+
+		if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct {
+			elements := []json.RawMessage{}
+			if err := json.Unmarshal(raw[key], &elements); err != nil {
+				continue
+			}
+
+			// Elements that aren't objects, like texture filenames, have no fields to check.
+			for i, element := range elements {
+				object := map[string]json.RawMessage{}
+				if err := json.Unmarshal(element, &object); err == nil {
+					errs = append(errs, validateFields(fmt.Sprintf("%s[%d]", fieldPath, i), object, fieldType.Elem())...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// jsonFields maps the JSON names of a struct's fields to their types, including fields of embedded structs.
+func jsonFields(structType reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name, fieldType := range jsonFields(field.Type) {
+				fields[name] = fieldType
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = field.Type
+		}
+	}
+
+	return fields
+}
+
+func sortedKeys(raw map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// closestField returns the known field closest to the given name, or an empty string if none is close enough to be a
+// likely typo.
+func closestField(name string, fields map[string]reflect.Type) string {
+	const maxDistance = 2
+
+	closest := ""
+	closestDistance := maxDistance + 1
+	for field := range fields {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(field))
+		if distance < closestDistance || (distance == closestDistance && field < closest) {
+			closest = field
+			closestDistance = distance
+		}
+	}
+
+	if closestDistance > maxDistance {
+		return ""
+	}
+
+	return closest
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// ValidateLevelData checks a decoded level for problems that would make it unplayable or crash the engine.
+func ValidateLevelData(ld LevelData) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if ld.Width <= 0 {
+		errs = append(errs, newFieldError("width", "must be positive, got %d", ld.Width))
+	}
+	if ld.Height <= 0 {
+		errs = append(errs, newFieldError("height", "must be positive, got %d", ld.Height))
+	}
+
+	errs = append(errs, validateGrid("map", ld.Map, ld.Width, ld.Height, true)...)
+	// Nothing else can be checked reliably against a broken map.
+	if len(errs) > 0 {
+		return errs
+	}
+
+	textureCount := len(ld.TextureSources)
+	errs = append(errs, validateTextureSources(ld.TextureSources)...)
+	wallErrs, wallTypes := validateWalls(ld.Walls, textureCount)
+	errs = append(errs, wallErrs...)
+	errs = append(errs, validateTextureIds("map", ld.Map, textureCount, wallTypes)...)
+	errs = append(errs, validateBorder(ld.Map)...)
+
+	if ld.FloorTextures != nil {
+		floorErrs := validateGrid("floorTextures", ld.FloorTextures, ld.Width, ld.Height, false)
+		if len(floorErrs) == 0 {
+			floorErrs = validateTextureIds("floorTextures", ld.FloorTextures, textureCount, nil)
+		}
+		errs = append(errs, floorErrs...)
+	}
+
+	if ld.CeilingTextures != nil {
+		ceilingErrs := validateGrid("ceilingTextures", ld.CeilingTextures, ld.Width, ld.Height, false)
+		if len(ceilingErrs) == 0 {
+			ceilingErrs = validateTextureIds("ceilingTextures", ld.CeilingTextures, textureCount, nil)
+		}
+		errs = append(errs, ceilingErrs...)
+	}
+
+	errs = append(errs, validateFog(ld.Fog)...)
+	errs = append(errs, validateWallShading(ld.WallShading)...)
+
+	// Player spawn
+	playerCol := int(ld.PlayerX)
+	playerRow := int(ld.PlayerY)
+	if !ld.isInBounds(ld.PlayerX, ld.PlayerY) {
+		errs = append(errs, newFieldError("playerX/playerY", "spawn (%g, %g) is outside of the map", ld.PlayerX,
+			ld.PlayerY))
+	} else if ld.Map[playerRow][playerCol] != 0 {
+		errs = append(errs, newCellError("playerX/playerY", playerRow, playerCol, "spawn (%g, %g) is inside a wall",
+			ld.PlayerX, ld.PlayerY))
+	} else if row, col, ok := ld.findWallNear(ld.PlayerX, ld.PlayerY, config.PLAYER_RADIUS); ok {
+		errs = append(errs, newCellError("playerX/playerY", row, col, "spawn (%g, %g) is closer than the player "+
+			"radius (%g) to a wall", ld.PlayerX, ld.PlayerY, config.PLAYER_RADIUS))
+	}
+
+	for i, sprite := range ld.Sprites {
+		field := fmt.Sprintf("sprites[%d]", i)
+		if !ld.isInBounds(sprite.X, sprite.Y) {
+			errs = append(errs, newFieldError(field, "position (%g, %g) is outside of the map", sprite.X, sprite.Y))
+		}
+		if err, ok := validateRequiredTextureId(field+".texture", sprite.TextureId, textureCount); !ok {
+			errs = append(errs, err)
+		}
+	}
+
+	for i, door := range ld.Doors {
+		field := fmt.Sprintf("doors[%d]", i)
+		if !ld.isInBounds(float64(door.X), float64(door.Y)) {
+			errs = append(errs, newFieldError(field, "position (%d, %d) is outside of the map", door.X, door.Y))
+		} else if ld.Map[door.Y][door.X] != 0 {
+			errs = append(errs, newCellError(field, door.Y, door.X, "door is placed inside a wall"))
+		} else if horizontal, vertical := ld.isDoorFramed(door, 1, 0), ld.isDoorFramed(door, 0, 1); horizontal == vertical {
+			errs = append(errs, newCellError(field, door.Y, door.X, "door must be framed by walls either on its left "+
+				"and right or above and below"))
+		}
+		if err, ok := validateRequiredTextureId(field+".texture", door.TextureId, textureCount); !ok {
+			errs = append(errs, err)
+		}
+		if door.Speed < 0.0 {
+			errs = append(errs, newFieldError(field+".speed", "must not be negative, got %g", door.Speed))
+		}
+	}
+
+	return errs
+}
+
+func (ld LevelData) isInBounds(x, y float64) bool {
+	return x >= 0.0 && y >= 0.0 && x < float64(ld.Width) && y < float64(ld.Height)
+}
+
+// isDoorFramed returns true if the door has walls on both sides along the given axis. Cells outside of the map aren't
+// walls.
+func (ld LevelData) isDoorFramed(door DoorData, axisX, axisY int) bool {
+	isWall := func(x, y int) bool {
+		return y >= 0 && y < len(ld.Map) && x >= 0 && x < len(ld.Map[y]) && ld.Map[y][x] > 0
+	}
+
+	return isWall(door.X-axisX, door.Y-axisY) && isWall(door.X+axisX, door.Y+axisY)
+}
+
+// findWallNear returns the first wall cell a circle of the given radius overlaps. Like the game's collision, a circle
+// merely touching a wall doesn't overlap it. Cells outside of the map count as walls.
+func (ld LevelData) findWallNear(x, y, radius float64) (int, int, bool) {
+	for row := int(math.Floor(y - radius)); row <= int(math.Floor(y+radius)); row++ {
+		for col := int(math.Floor(x - radius)); col <= int(math.Floor(x+radius)); col++ {
+			inMap := row >= 0 && col >= 0 && row < len(ld.Map) && col < len(ld.Map[row])
+			if inMap && ld.Map[row][col] == 0 {
+				continue
+			}
+
+			distX := max(float64(col)-x, 0.0, x-float64(col+1))
+			distY := max(float64(row)-y, 0.0, y-float64(row+1))
+			if math.Hypot(distX, distY) < radius {
+				return row, col, true
+			}
+		}
+	}
+
+	return -1, -1, false
+}
+
+// validateGrid checks that a per-cell grid matches the level's dimensions.
+func validateGrid(field string, grid [][]int, width, height int, required bool) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if len(grid) == 0 && required {
+		return append(errs, newFieldError(field, "is missing or empty"))
+	}
+
+	if len(grid) != height {
+		errs = append(errs, newFieldError(field, "has %d rows, expected height %d", len(grid), height))
+	}
+
+	for row, cells := range grid {
+		if len(cells) != width {
+			errs = append(errs, newFieldError(fmt.Sprintf("%s[%d]", field, row), "has %d columns, expected width %d",
+				len(cells), width))
+		}
+	}
+
+	return errs
+}
+
+// validateTextureIds checks every id of a per-cell grid, except for defined wall types which don't index textures.
+func validateTextureIds(field string, grid [][]int, textureCount int, wallTypes map[int]bool) ValidationErrors {
+	errs := ValidationErrors{}
+
+	for row, cells := range grid {
+		for col, textureId := range cells {
+			if wallTypes[textureId] {
+				continue
+			}
+
+			if err, ok := validateTextureId(fmt.Sprintf("%s[%d][%d]", field, row, col), textureId, textureCount); !ok {
+				err.Row = row
+				err.Col = col
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateTextureId checks a texture id against the normal wall textures. Ids are 1 based, 0 meaning no texture. Levels
+// without textures render flat colours, any positive id goes.
+func validateTextureId(field string, textureId int, textureCount int) (ValidationError, bool) {
+	if textureId < 0 {
+		return newFieldError(field, "texture id %d must not be negative", textureId), false
+	}
+
+	if textureCount > 0 && textureId > textureCount {
+		return newFieldError(field, "texture id %d is out of range, the level has %d texture(s)", textureId,
+			textureCount), false
+	}
+
+	return ValidationError{}, true
+}
+
+// validateRequiredTextureId checks a texture id that can't be left out, for things that are always drawn textured.
+func validateRequiredTextureId(field string, textureId int, textureCount int) (ValidationError, bool) {
+	if textureId == 0 {
+		return newFieldError(field, "is missing, texture ids start at 1"), false
+	}
+
+	return validateTextureId(field, textureId, textureCount)
+}
+
+// validateBorder checks that the map's outer cells are all walls, so rays and the player can't leave the map.
+func validateBorder(levelMap [][]int) ValidationErrors {
+	errs := ValidationErrors{}
+	height := len(levelMap)
+	width := len(levelMap[0])
+
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			onBorder := row == 0 || col == 0 || row == height-1 || col == width-1
+			if onBorder && levelMap[row][col] == 0 {
+				errs = append(errs, newCellError(fmt.Sprintf("map[%d][%d]", row, col), row, col,
+					"border cell is open, the map must be enclosed by walls"))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateFog(fog FogData) ValidationErrors {
+	errs := ValidationErrors{}
+
+	switch fog.Mode {
+	case FOG_NONE:
+		return errs
+	case FOG_LINEAR:
+		if fog.Start < 0.0 || fog.End <= fog.Start {
+			errs = append(errs, newFieldError("fog", "linear fog needs 0 <= start < end, got start %g and end %g", fog.Start,
+				fog.End))
+		}
+	case FOG_EXPONENTIAL:
+		if fog.Density <= 0.0 {
+			errs = append(errs, newFieldError("fog.density", "must be positive, got %g", fog.Density))
+		}
+	default:
+		errs = append(errs, newFieldError("fog.mode", "unknown mode %q, expected %q or %q", fog.Mode, FOG_LINEAR,
+			FOG_EXPONENTIAL))
+	}
+
+	for i, component := range fog.Color {
+		if component < 0 || component > 255 {
+			errs = append(errs, newFieldError(fmt.Sprintf("fog.color[%d]", i), "must be between 0 and 255, got %d",
+				component))
+		}
+	}
+
+	return errs
+}
+
+func validateWallShading(shading WallShadingData) ValidationErrors {
+	errs := ValidationErrors{}
+
+	faces := []struct {
+		name  string
+		value float64
+	}{
+		{"north", shading.North},
+		{"south", shading.South},
+		{"east", shading.East},
+		{"west", shading.West},
+	}
+	for _, face := range faces {
+		if face.value < 0.0 || face.value > 1.0 {
+			errs = append(errs, newFieldError("wallShading."+face.name, "must be between 0 and 1, got %g", face.value))
+		}
+	}
+
+	return errs
+}
+
+// validateWalls checks the wall definitions, returns the defined wall types along with the errors.
+func validateWalls(walls []WallData, textureCount int) (ValidationErrors, map[int]bool) {
+	errs := ValidationErrors{}
+	wallTypes := map[int]bool{}
+
+	for i, wall := range walls {
+		field := fmt.Sprintf("walls[%d]", i)
+		if wall.Type <= 0 {
+			errs = append(errs, newFieldError(field+".type", "must be positive, got %d", wall.Type))
+			continue
+		}
+		if wallTypes[wall.Type] {
+			errs = append(errs, newFieldError(field+".type", "wall type %d is defined more than once", wall.Type))
+			continue
+		}
+		wallTypes[wall.Type] = true
+
+		// Faces without a texture use the wall's texture, only report it once.
+		resolved := wall.ResolveFaceTextures()
+		faces := []struct {
+			name      string
+			textureId int
+		}{
+			{"texture", resolved.Texture},
+			{"north", wall.North},
+			{"south", wall.South},
+			{"east", wall.East},
+			{"west", wall.West},
+		}
+		for _, face := range faces {
+			if err, ok := validateTextureId(field+"."+face.name, face.textureId, textureCount); !ok {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs, wallTypes
+}
+
+func validateTextureSources(sources []TextureSourceData) ValidationErrors {
+	errs := ValidationErrors{}
+
+	for i, source := range sources {
+		field := fmt.Sprintf("textures[%d]", i)
+		if source.Filename != "" {
+			continue
+		}
+
+		switch {
+		case len(source.Frames) > 0 && source.Strip != "":
+			errs = append(errs, newFieldError(field, "animated texture has both frames and a strip, expected one"))
+		case len(source.Frames) == 0 && source.Strip == "":
+			errs = append(errs, newFieldError(field, "expected a filename, frames or a strip"))
+		case source.Strip != "" && source.FrameCount <= 0:
+			errs = append(errs, newFieldError(field+".frameCount", "must be positive for a strip, got %d",
+				source.FrameCount))
+		}
+
+		if source.FrameRate <= 0.0 {
+			errs = append(errs, newFieldError(field+".frameRate", "must be positive, got %g", source.FrameRate))
+		}
+	}
+
+	return errs
+}
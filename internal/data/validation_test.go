@@ -0,0 +1,375 @@
+package data
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newValidationTestLevel() LevelData {
+	return LevelData{
+		Width:  4,
+		Height: 4,
+		Map: [][]int{
+			{1, 1, 1, 1},
+			{1, 0, 0, 1},
+			{1, 0, 2, 1},
+			{1, 1, 1, 1},
+		},
+		TextureSources: []TextureSourceData{{Filename: "a.png"}, {Filename: "b.png"}},
+		PlayerCoordData: PlayerCoordData{
+			PlayerX: 1.5,
+			PlayerY: 1.5,
+		},
+	}
+}
+
+func Test_ValidateLevelData(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(ld *LevelData)
+		expected ValidationErrors
+	}{
+		{
+			name:     "valid",
+			modify:   func(ld *LevelData) {},
+			expected: ValidationErrors{},
+		},
+		{
+			name: "bad_dimensions",
+			modify: func(ld *LevelData) {
+				ld.Width = 5
+				ld.Height = 0
+			},
+			expected: ValidationErrors{
+				{Field: "height", Row: -1, Col: -1, Reason: "must be positive, got 0"},
+				{Field: "map", Row: -1, Col: -1, Reason: "has 4 rows, expected height 0"},
+				{Field: "map[0]", Row: -1, Col: -1, Reason: "has 4 columns, expected width 5"},
+				{Field: "map[1]", Row: -1, Col: -1, Reason: "has 4 columns, expected width 5"},
+				{Field: "map[2]", Row: -1, Col: -1, Reason: "has 4 columns, expected width 5"},
+				{Field: "map[3]", Row: -1, Col: -1, Reason: "has 4 columns, expected width 5"},
+			},
+		},
+		{
+			name: "ragged_row",
+			modify: func(ld *LevelData) {
+				ld.Map[2] = []int{1, 0, 1}
+			},
+			expected: ValidationErrors{
+				{Field: "map[2]", Row: -1, Col: -1, Reason: "has 3 columns, expected width 4"},
+			},
+		},
+		{
+			name: "missing_map",
+			modify: func(ld *LevelData) {
+				ld.Map = nil
+			},
+			expected: ValidationErrors{
+				{Field: "map", Row: -1, Col: -1, Reason: "is missing or empty"},
+			},
+		},
+		{
+			name: "texture_out_of_range",
+			modify: func(ld *LevelData) {
+				ld.Map[2][2] = 3
+			},
+			expected: ValidationErrors{
+				{Field: "map[2][2]", Row: 2, Col: 2, Reason: "texture id 3 is out of range, the level has 2 texture(s)"},
+			},
+		},
+		{
+			name: "untextured_any_id",
+			modify: func(ld *LevelData) {
+				ld.TextureSources = nil
+				ld.Map[2][2] = 3
+			},
+			expected: ValidationErrors{},
+		},
+		{
+			name: "negative_texture",
+			modify: func(ld *LevelData) {
+				ld.Map[0][1] = -1
+			},
+			expected: ValidationErrors{
+				{Field: "map[0][1]", Row: 0, Col: 1, Reason: "texture id -1 must not be negative"},
+			},
+		},
+		{
+			name: "open_border",
+			modify: func(ld *LevelData) {
+				ld.Map[1][3] = 0
+				ld.Map[3][2] = 0
+			},
+			expected: ValidationErrors{
+				{Field: "map[1][3]", Row: 1, Col: 3, Reason: "border cell is open, the map must be enclosed by walls"},
+				{Field: "map[3][2]", Row: 3, Col: 2, Reason: "border cell is open, the map must be enclosed by walls"},
+			},
+		},
+		{
+			name: "spawn_in_wall",
+			modify: func(ld *LevelData) {
+				ld.PlayerX = 2.5
+				ld.PlayerY = 2.5
+			},
+			expected: ValidationErrors{
+				{Field: "playerX/playerY", Row: 2, Col: 2, Reason: "spawn (2.5, 2.5) is inside a wall"},
+			},
+		},
+		{
+			name: "spawn_on_cell_edge_next_to_wall",
+			modify: func(ld *LevelData) {
+				ld.PlayerX = 1.0
+			},
+			expected: ValidationErrors{
+				{Field: "playerX/playerY", Row: 1, Col: 0,
+					Reason: "spawn (1, 1.5) is closer than the player radius (0.25) to a wall"},
+			},
+		},
+		{
+			name: "spawn_touching_wall",
+			modify: func(ld *LevelData) {
+				ld.PlayerX = 1.25
+			},
+			expected: ValidationErrors{},
+		},
+		{
+			name: "spawn_outside",
+			modify: func(ld *LevelData) {
+				ld.PlayerX = -0.5
+			},
+			expected: ValidationErrors{
+				{Field: "playerX/playerY", Row: -1, Col: -1, Reason: "spawn (-0.5, 1.5) is outside of the map"},
+			},
+		},
+		{
+			name: "floor_textures",
+			modify: func(ld *LevelData) {
+				ld.FloorTextures = [][]int{{0, 0, 0, 0}, {0, 1, 5, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}
+				ld.CeilingTextures = [][]int{{0, 0, 0, 0}}
+			},
+			expected: ValidationErrors{
+				{Field: "floorTextures[1][2]", Row: 1, Col: 2,
+					Reason: "texture id 5 is out of range, the level has 2 texture(s)"},
+				{Field: "ceilingTextures", Row: -1, Col: -1, Reason: "has 1 rows, expected height 4"},
+			},
+		},
+		{
+			name: "sprites_and_doors",
+			modify: func(ld *LevelData) {
+				ld.Sprites = []SpriteData{{X: 1.5, Y: 2.5, TextureId: 1}, {X: 4.5, Y: 1.5, TextureId: 7}}
+				ld.Doors = []DoorData{{X: 2, Y: 1, TextureId: 2, Speed: 1.0}, {X: 2, Y: 2, TextureId: 1, Speed: -1.0}}
+			},
+			expected: ValidationErrors{
+				{Field: "sprites[1]", Row: -1, Col: -1, Reason: "position (4.5, 1.5) is outside of the map"},
+				{Field: "sprites[1].texture", Row: -1, Col: -1,
+					Reason: "texture id 7 is out of range, the level has 2 texture(s)"},
+				{Field: "doors[1]", Row: 2, Col: 2, Reason: "door is placed inside a wall"},
+				{Field: "doors[1].speed", Row: -1, Col: -1, Reason: "must not be negative, got -1"},
+			},
+		},
+		{
+			name: "sprite_without_texture",
+			modify: func(ld *LevelData) {
+				ld.Sprites = []SpriteData{{X: 1.5, Y: 2.5}}
+			},
+			expected: ValidationErrors{
+				{Field: "sprites[0].texture", Row: -1, Col: -1, Reason: "is missing, texture ids start at 1"},
+			},
+		},
+		{
+			name: "door_framing",
+			modify: func(ld *LevelData) {
+				ld.Width = 5
+				ld.Height = 5
+				ld.Map = [][]int{
+					{1, 1, 1, 1, 1},
+					{1, 0, 0, 1, 1},
+					{1, 0, 0, 0, 1},
+					{1, 1, 0, 1, 1},
+					{1, 1, 1, 1, 1},
+				}
+				ld.Doors = []DoorData{
+					{X: 2, Y: 1, TextureId: 1},
+					{X: 1, Y: 2, TextureId: 1},
+					{X: 2, Y: 2, TextureId: 1},
+					{X: 2, Y: 3, TextureId: 1},
+				}
+			},
+			expected: ValidationErrors{
+				{Field: "doors[0]", Row: 1, Col: 2, Reason: "door must be framed by walls either on its left and right " +
+					"or above and below"},
+				{Field: "doors[1]", Row: 2, Col: 1, Reason: "door must be framed by walls either on its left and right " +
+					"or above and below"},
+				{Field: "doors[2]", Row: 2, Col: 2, Reason: "door must be framed by walls either on its left and right " +
+					"or above and below"},
+			},
+		},
+		{
+			name: "door_without_texture",
+			modify: func(ld *LevelData) {
+				ld.Doors = []DoorData{{X: 2, Y: 1, Speed: 1.0}}
+			},
+			expected: ValidationErrors{
+				{Field: "doors[0].texture", Row: -1, Col: -1, Reason: "is missing, texture ids start at 1"},
+			},
+		},
+		{
+			name: "fog",
+			modify: func(ld *LevelData) {
+				ld.Fog = FogData{Mode: FOG_LINEAR, Color: [3]int{0, 300, 0}, Start: 4.0, End: 2.0}
+			},
+			expected: ValidationErrors{
+				{Field: "fog", Row: -1, Col: -1, Reason: "linear fog needs 0 <= start < end, got start 4 and end 2"},
+				{Field: "fog.color[1]", Row: -1, Col: -1, Reason: "must be between 0 and 255, got 300"},
+			},
+		},
+		{
+			name: "wall_definitions",
+			modify: func(ld *LevelData) {
+				// Wall type 5 isn't a texture id, but it's defined.
+				ld.Map[2][2] = 5
+				ld.Walls = []WallData{
+					{Type: 5, Texture: 1, North: 2},
+					{Type: 6, East: 3},
+					{Type: 5},
+					{Type: 0, Texture: 1},
+				}
+			},
+			expected: ValidationErrors{
+				{Field: "walls[1].texture", Row: -1, Col: -1,
+					Reason: "texture id 6 is out of range, the level has 2 texture(s)"},
+				{Field: "walls[1].east", Row: -1, Col: -1,
+					Reason: "texture id 3 is out of range, the level has 2 texture(s)"},
+				{Field: "walls[2].type", Row: -1, Col: -1, Reason: "wall type 5 is defined more than once"},
+				{Field: "walls[3].type", Row: -1, Col: -1, Reason: "must be positive, got 0"},
+			},
+		},
+		{
+			name: "wall_shading",
+			modify: func(ld *LevelData) {
+				ld.WallShading = WallShadingData{North: 0.5, South: -0.5, East: 1.0, West: 1.5}
+			},
+			expected: ValidationErrors{
+				{Field: "wallShading.south", Row: -1, Col: -1, Reason: "must be between 0 and 1, got -0.5"},
+				{Field: "wallShading.west", Row: -1, Col: -1, Reason: "must be between 0 and 1, got 1.5"},
+			},
+		},
+		{
+			name: "animated_textures",
+			modify: func(ld *LevelData) {
+				ld.TextureSources = append(ld.TextureSources,
+					TextureSourceData{Frames: []string{"c.png", "d.png"}, FrameRate: 8.0},
+					TextureSourceData{Frames: []string{"c.png"}, Strip: "e.png", FrameCount: 2, FrameRate: 8.0},
+					TextureSourceData{Strip: "e.png"},
+					TextureSourceData{FrameRate: 1.0},
+				)
+			},
+			expected: ValidationErrors{
+				{Field: "textures[3]", Row: -1, Col: -1, Reason: "animated texture has both frames and a strip, expected one"},
+				{Field: "textures[4].frameCount", Row: -1, Col: -1, Reason: "must be positive for a strip, got 0"},
+				{Field: "textures[4].frameRate", Row: -1, Col: -1, Reason: "must be positive, got 0"},
+				{Field: "textures[5]", Row: -1, Col: -1, Reason: "expected a filename, frames or a strip"},
+			},
+		},
+		{
+			name: "fog_mode",
+			modify: func(ld *LevelData) {
+				ld.Fog = FogData{Mode: "exp", Density: 0.5}
+			},
+			expected: ValidationErrors{
+				{Field: "fog.mode", Row: -1, Col: -1, Reason: `unknown mode "exp", expected "linear" or "exponential"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			levelData := newValidationTestLevel()
+			tc.modify(&levelData)
+
+			got := ValidateLevelData(levelData)
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_ValidateLevelContent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected ValidationErrors
+	}{
+		{
+			name:     "known_fields",
+			data:     `{"name": "test", "playerX": 1.0, "sprites": [{"x": 1.0, "texture": 1}]}`,
+			expected: ValidationErrors{},
+		},
+		{
+			name: "typos",
+			data: `{"playertAngle": 0.0, "Ambientlight": 1.0, "comment": 1}`,
+			expected: ValidationErrors{
+				{Field: "Ambientlight", Row: -1, Col: -1, Reason: `unknown field, did you mean "ambientLight"?`},
+				{Field: "comment", Row: -1, Col: -1, Reason: "unknown field"},
+				{Field: "playertAngle", Row: -1, Col: -1, Reason: `unknown field, did you mean "playerAngle"?`},
+			},
+		},
+		{
+			name: "animated_texture",
+			data: `{"textures": ["a.png", {"strip": "b.png", "frameCount": 4, "framerate": 8.0}]}`,
+			expected: ValidationErrors{
+				{Field: "textures[1].framerate", Row: -1, Col: -1, Reason: `unknown field, did you mean "frameRate"?`},
+			},
+		},
+		{
+			name: "nested",
+			data: `{"doors": [{"x": 1}, {"x": 2, "autoclose": 3.0}], "fog": {"mode": "linear", "colour": [0, 0, 0]}}`,
+			expected: ValidationErrors{
+				{Field: "doors[1].autoclose", Row: -1, Col: -1, Reason: "unknown field"},
+				{Field: "fog.colour", Row: -1, Col: -1, Reason: `unknown field, did you mean "color"?`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValidateLevelContent([]byte(tc.data))
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+// The shipped levels must stay valid.
+func Test_ValidateDemoLevels(t *testing.T) {
+	filenames, err := filepath.Glob(filepath.Join("..", "..", "assets", "demo", "*.json"))
+	if err != nil || len(filenames) == 0 {
+		t.Fatalf("Failed to find demo levels: %v", err)
+	}
+
+	for _, filename := range filenames {
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", filename, err)
+			}
+
+			levelData := LevelData{}
+			if err := json.Unmarshal(content, &levelData); err != nil {
+				t.Fatalf("Failed to decode %s: %v", filename, err)
+			}
+
+			errs := append(ValidateLevelContent(content), ValidateLevelData(levelData)...)
+			if len(errs) > 0 {
+				t.Errorf("%s is invalid: %v", filename, errs)
+			}
+		})
+	}
+}
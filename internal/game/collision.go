@@ -0,0 +1,104 @@
+package game
+
+import (
+	"math"
+)
+
+// CellSolidFunc returns true if the map cell at the given coordinates blocks movement.
+type CellSolidFunc func(cellX, cellY int) bool
+
+// MoveCircle moves a circle of the given radius by (dx, dy) through a grid of cells, stopping it against solid cells.
+// The X and Y axes are resolved separately so a circle moving into a wall at an angle slides along it. Long moves are
+// split in steps no longer than the radius so the circle can't tunnel through thin walls. Returns the new position.
+func MoveCircle(x, y, radius, dx, dy float64, isSolid CellSolidFunc) (float64, float64) {
+	distance := math.Hypot(dx, dy)
+	if distance == 0.0 {
+		return x, y
+	}
+
+	steps := 1
+	if radius > 0.0 {
+		steps = int(math.Ceil(distance / radius))
+	}
+	stepX := dx / float64(steps)
+	stepY := dy / float64(steps)
+
+	for i := 0; i < steps; i++ {
+		x = resolveCircleAxis(x, y, radius, stepX, isSolid, false)
+		y = resolveCircleAxis(y, x, radius, stepY, isSolid, true)
+	}
+
+	return x, y
+}
+
+// resolveCircleAxis moves a circle along a single axis and pulls it back out of any solid cell it ends up overlapping.
+// The position is along the moving axis, the cross position along the other. Swapped means the moving axis is Y.
+func resolveCircleAxis(position, cross, radius, delta float64, isSolid CellSolidFunc, swapped bool) float64 {
+	if delta == 0.0 {
+		return position
+	}
+
+	target := position + delta
+	minCell := int(math.Floor(target - radius))
+	maxCell := int(math.Floor(target + radius))
+	// Only cells on the leading side of the move can stop it. Cells behind, which the circle may touch or even overlap,
+	// must not hold it back.
+	if delta > 0.0 {
+		minCell = max(minCell, int(math.Floor(position+radius)))
+	} else {
+		maxCell = min(maxCell, int(math.Floor(position-radius)))
+	}
+	minCross := int(math.Floor(cross - radius))
+	maxCross := int(math.Floor(cross + radius))
+
+	for cell := minCell; cell <= maxCell; cell++ {
+		for crossCell := minCross; crossCell <= maxCross; crossCell++ {
+			solid := false
+			if swapped {
+				solid = isSolid(crossCell, cell)
+			} else {
+				solid = isSolid(cell, crossCell)
+			}
+			if !solid {
+				continue
+			}
+
+			// Distance from the circle's centre to the cell along the cross axis, 0 when the centre is level with it.
+			crossDistance := max(float64(crossCell)-cross, 0.0, cross-float64(crossCell+1))
+			if crossDistance >= radius {
+				continue
+			}
+
+			// How far the centre must stay from the cell's edge along the moving axis to only touch it.
+			clearance := math.Sqrt(radius*radius - crossDistance*crossDistance)
+			if delta > 0.0 {
+				limit := float64(cell) - clearance
+				if target > limit {
+					target = max(limit, position)
+				}
+			} else {
+				limit := float64(cell+1) + clearance
+				if target < limit {
+					target = min(limit, position)
+				}
+			}
+		}
+	}
+
+	return target
+}
+
+// MoveActor moves an actor of the given radius through the level, sliding along walls and closed doors.
+func (g *Game) MoveActor(x, y, radius, dx, dy float64) (float64, float64) {
+	return MoveCircle(x, y, radius, dx, dy, g.isCellSolid)
+}
+
+func (g *Game) isCellSolid(cellX, cellY int) bool {
+	// Negative cells would be truncated into the map.
+	if cellX < 0 || cellY < 0 {
+		return true
+	}
+
+	hit, _ := g.CheckWallCollision(float64(cellX)+0.5, float64(cellY)+0.5)
+	return hit
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// Room of 2x3 cells with a one cell wide corridor leading east.
+var collisionTestMap = [][]int{
+	{1, 1, 1, 1, 1, 1, 1},
+	{1, 0, 0, 1, 1, 1, 1},
+	{1, 0, 0, 0, 0, 0, 1},
+	{1, 0, 0, 1, 1, 1, 1},
+	{1, 1, 1, 1, 1, 1, 1},
+}
+
+func isCollisionTestCellSolid(cellX, cellY int) bool {
+	if cellX < 0 || cellY < 0 || cellY >= len(collisionTestMap) || cellX >= len(collisionTestMap[0]) {
+		return true
+	}
+
+	return collisionTestMap[cellY][cellX] > 0
+}
+
+func Test_MoveCircle(t *testing.T) {
+	testCases := []struct {
+		name   string
+		x      float64
+		y      float64
+		radius float64
+		dx     float64
+		dy     float64
+		wantX  float64
+		wantY  float64
+	}{
+		{
+			name:   "free_move",
+			x:      1.5,
+			y:      1.5,
+			radius: 0.25,
+			dx:     0.2,
+			dy:     0.3,
+			wantX:  1.7,
+			wantY:  1.8,
+		},
+		{
+			name:   "head_on_wall",
+			x:      1.5,
+			y:      1.5,
+			radius: 0.25,
+			dx:     -1.0,
+			dy:     0.0,
+			wantX:  1.25,
+			wantY:  1.5,
+		},
+		{
+			name:   "diagonal_slides_along_wall",
+			x:      1.5,
+			y:      1.5,
+			radius: 0.25,
+			dx:     -1.0,
+			dy:     1.0,
+			wantX:  1.25,
+			wantY:  2.5,
+		},
+		{
+			name:   "diagonal_into_inner_corner",
+			x:      1.5,
+			y:      1.5,
+			radius: 0.25,
+			dx:     -1.0,
+			dy:     -1.0,
+			wantX:  1.25,
+			wantY:  1.25,
+		},
+		{
+			name:   "long_move_does_not_tunnel",
+			x:      2.5,
+			y:      1.5,
+			radius: 0.25,
+			dx:     0.0,
+			dy:     -10.0,
+			wantX:  2.5,
+			wantY:  1.25,
+		},
+		{
+			name:   "narrow_corridor",
+			x:      1.5,
+			y:      2.5,
+			radius: 0.25,
+			dx:     5.0,
+			dy:     0.0,
+			wantX:  5.75,
+			wantY:  2.5,
+		},
+		{
+			name:   "away_from_touching_wall",
+			x:      1.25,
+			y:      1.5,
+			radius: 0.25,
+			dx:     0.2,
+			dy:     0.0,
+			wantX:  1.45,
+			wantY:  1.5,
+		},
+		{
+			name:   "leaves_overlapping_start",
+			x:      1.1,
+			y:      1.5,
+			radius: 0.25,
+			dx:     0.3,
+			dy:     0.0,
+			wantX:  1.4,
+			wantY:  1.5,
+		},
+		{
+			name:   "no_deeper_into_overlapping_start",
+			x:      1.1,
+			y:      1.5,
+			radius: 0.25,
+			dx:     -0.1,
+			dy:     0.0,
+			wantX:  1.1,
+			wantY:  1.5,
+		},
+		{
+			name:   "too_wide_for_corridor",
+			x:      1.5,
+			y:      2.5,
+			radius: 0.6,
+			dx:     3.0,
+			dy:     0.0,
+			wantX:  3.0 - math.Sqrt(0.6*0.6-0.5*0.5),
+			wantY:  2.5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotX, gotY := MoveCircle(tc.x, tc.y, tc.radius, tc.dx, tc.dy, isCollisionTestCellSolid)
+
+			if math.Abs(gotX-tc.wantX) > 0.000001 || math.Abs(gotY-tc.wantY) > 0.000001 {
+				t.Errorf("Expected (%f, %f), got (%f, %f)", tc.wantX, tc.wantY, gotX, gotY)
+			}
+		})
+	}
+}
+
+// A circle close to a lone wall cell's corner, overlapping it on both axes but not touching it, moves away freely.
+func Test_MoveCircleAwayFromCorner(t *testing.T) {
+	isSolid := func(cellX, cellY int) bool {
+		return cellX == 0 && cellY == 0
+	}
+
+	for _, move := range [][2]float64{{0.01, 0.0}, {0.0, 0.01}, {0.01, 0.01}} {
+		gotX, gotY := MoveCircle(1.2, 1.2, 0.25, move[0], move[1], isSolid)
+
+		wantX, wantY := 1.2+move[0], 1.2+move[1]
+		if math.Abs(gotX-wantX) > 0.000001 || math.Abs(gotY-wantY) > 0.000001 {
+			t.Errorf("Expected (%f, %f), got (%f, %f)", wantX, wantY, gotX, gotY)
+		}
+	}
+}
+
+// Moving in any direction, including diagonally around the corridor's corners, never ends up overlapping a wall.
+func Test_MoveCircleNeverOverlaps(t *testing.T) {
+	const radius = 0.3
+	starts := [][2]float64{{1.5, 1.5}, {2.5, 2.5}, {1.5, 3.5}, {4.5, 2.5}}
+
+	for _, start := range starts {
+		for angle := 0.0; angle < 360.0; angle += 15.0 {
+			x, y := start[0], start[1]
+			dx := 0.05 * math.Cos(angle*math.Pi/180.0)
+			dy := -0.05 * math.Sin(angle*math.Pi/180.0)
+
+			for i := 0; i < 100; i++ {
+				x, y = MoveCircle(x, y, radius, dx, dy, isCollisionTestCellSolid)
+
+				for cellY, row := range collisionTestMap {
+					for cellX, cell := range row {
+						if cell == 0 {
+							continue
+						}
+
+						distX := max(float64(cellX)-x, 0.0, x-float64(cellX+1))
+						distY := max(float64(cellY)-y, 0.0, y-float64(cellY+1))
+						if math.Hypot(distX, distY) < radius-0.000001 {
+							t.Fatalf("Start %v angle %.0f: circle at (%f, %f) overlaps cell (%d, %d)", start, angle, x,
+								y, cellX, cellY)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+const (
+	// Doors stop being solid once they're open more than this fraction.
+	DOOR_SOLID_FRACTION = 0.5
+)
+
+type doorState int
+
+const (
+	doorClosed doorState = iota
+	doorOpening
+	doorOpen
+	doorClosing
+)
+
+type doorCell struct {
+	x int
+	y int
+}
+
+type door struct {
+	data.DoorData
+	horizontal   bool
+	state        doorState
+	openFraction float64
+	openTime     float64
+}
+
+// newDoors creates the doors of a level, indexed by cell. A door spans along the X axis when it has walls on its left
+// and right, along the Y axis otherwise. Level validation makes sure doors are framed by walls on exactly one axis.
+func newDoors(levelData data.LevelData) map[doorCell]*door {
+	doors := make(map[doorCell]*door, len(levelData.Doors))
+
+	isWall := func(x, y int) bool {
+		if y < 0 || y >= len(levelData.Map) || x < 0 || x >= len(levelData.Map[y]) {
+			return false
+		}
+		return levelData.Map[y][x] > 0
+	}
+
+	for _, doorData := range levelData.Doors {
+		doors[doorCell{x: doorData.X, y: doorData.Y}] = &door{
+			DoorData:   doorData,
+			horizontal: isWall(doorData.X-1, doorData.Y) && isWall(doorData.X+1, doorData.Y),
+		}
+	}
+
+	return doors
+}
+
+func (d *door) open() {
+	if d.state == doorClosed || d.state == doorClosing {
+		d.state = doorOpening
+	}
+}
+
+// update advances the door's animation by elapsed seconds. Doors don't close on a player standing in their cell.
+func (d *door) update(elapsed float64, playerInCell bool) {
+	switch d.state {
+	case doorOpening:
+		d.openFraction += d.Speed * elapsed
+		if d.openFraction >= 1.0 {
+			d.openFraction = 1.0
+			d.openTime = 0.0
+			d.state = doorOpen
+		}
+
+	case doorOpen:
+		d.openTime += elapsed
+		if d.AutoCloseDelay > 0.0 && d.openTime >= d.AutoCloseDelay && !playerInCell {
+			d.state = doorClosing
+		}
+
+	case doorClosing:
+		if playerInCell {
+			d.state = doorOpening
+			break
+		}
+
+		d.openFraction -= d.Speed * elapsed
+		if d.openFraction <= 0.0 {
+			d.openFraction = 0.0
+			d.state = doorClosed
+		}
+	}
+}
+
+func (d door) isSolid() bool {
+	return d.openFraction < DOOR_SOLID_FRACTION
+}
+
+func (d door) getState() data.DoorState {
+	return data.DoorState{
+		X:            d.X,
+		Y:            d.Y,
+		TextureId:    d.TextureId,
+		Horizontal:   d.horizontal,
+		OpenFraction: d.openFraction,
+	}
+}
+
+func (g Game) findDoor(x, y float64) (*door, bool) {
+	if x < 0.0 || y < 0.0 {
+		return nil, false
+	}
+
+	d, ok := g.doors[doorCell{x: int(x), y: int(y)}]
+	return d, ok
+}
+
+// OpenDoor starts opening the door at the given coordinates, if there's one. Returns true if a door was found.
+func (g *Game) OpenDoor(x, y float64) bool {
+	d, ok := g.findDoor(x, y)
+	if ok {
+		d.open()
+	}
+
+	return ok
+}
+
+// GetDoorState returns the state of the door at the given coordinates, if there's one.
+func (g Game) GetDoorState(x, y float64) (data.DoorState, bool) {
+	d, ok := g.findDoor(x, y)
+	if !ok {
+		return data.DoorState{}, false
+	}
+
+	return d.getState(), true
+}
+
+func (g *Game) updateDoors(elapsed float64) {
+	pX := int(g.playerCoords.PlayerX)
+	pY := int(g.playerCoords.PlayerY)
+
+	for _, d := range g.doorList {
+		d.update(elapsed, d.X == pX && d.Y == pY)
+	}
+}
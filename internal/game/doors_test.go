@@ -0,0 +1,186 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/input"
+)
+
+func newDoorTestLevel() data.LevelData {
+	return data.LevelData{
+		Map: [][]int{
+			{1, 1, 1, 1, 1},
+			{1, 0, 0, 0, 1},
+			{1, 1, 0, 1, 1},
+			{1, 0, 0, 0, 1},
+			{1, 1, 1, 1, 1},
+		},
+		Doors: []data.DoorData{
+			{
+				X:              2,
+				Y:              2,
+				TextureId:      3,
+				Speed:          2.0,
+				AutoCloseDelay: 1.0,
+			},
+			{
+				X:         1,
+				Y:         3,
+				TextureId: 4,
+				Speed:     1.0,
+			},
+		},
+		PlayerCoordData: data.PlayerCoordData{
+			PlayerX: 2.5,
+			PlayerY: 1.5,
+		},
+	}
+}
+
+func Test_GameDoorOrientation(t *testing.T) {
+	g := NewGame(testGameConfig, newDoorTestLevel(), nil)
+
+	testCases := []struct {
+		name           string
+		x, y           float64
+		wantHorizontal bool
+	}{
+		{
+			name:           "walls_left_and_right",
+			x:              2.5,
+			y:              2.5,
+			wantHorizontal: true,
+		},
+		{
+			name:           "open_on_the_right",
+			x:              1.5,
+			y:              3.5,
+			wantHorizontal: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, ok := g.GetDoorState(tc.x, tc.y)
+
+			if !ok {
+				t.Fatalf("Expected a door at (%f, %f)", tc.x, tc.y)
+			}
+
+			if state.Horizontal != tc.wantHorizontal {
+				t.Errorf("Expected horizontal %t, got %t", tc.wantHorizontal, state.Horizontal)
+			}
+		})
+	}
+}
+
+func Test_GameDoorUpdate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		playerY       float64
+		updates       []float64
+		wantState     doorState
+		wantFraction  float64
+		wantCollision bool
+	}{
+		{
+			name:          "closed",
+			playerY:       1.5,
+			updates:       []float64{1.0},
+			wantState:     doorClosed,
+			wantFraction:  0.0,
+			wantCollision: true,
+		},
+		{
+			name:          "opening_mostly_closed",
+			playerY:       1.5,
+			updates:       []float64{0.1},
+			wantState:     doorOpening,
+			wantFraction:  0.2,
+			wantCollision: true,
+		},
+		{
+			name:          "opening_mostly_open",
+			playerY:       1.5,
+			updates:       []float64{0.1, 0.2},
+			wantState:     doorOpening,
+			wantFraction:  0.6,
+			wantCollision: false,
+		},
+		{
+			name:          "open",
+			playerY:       1.5,
+			updates:       []float64{0.5, 0.5},
+			wantState:     doorOpen,
+			wantFraction:  1.0,
+			wantCollision: false,
+		},
+		{
+			name:          "auto_close",
+			playerY:       1.5,
+			updates:       []float64{0.5, 1.0, 0.3},
+			wantState:     doorClosing,
+			wantFraction:  0.4,
+			wantCollision: true,
+		},
+		{
+			name:          "player_blocks_auto_close",
+			playerY:       2.5,
+			updates:       []float64{0.5, 1.0, 0.25},
+			wantState:     doorOpen,
+			wantFraction:  1.0,
+			wantCollision: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGame(testGameConfig, newDoorTestLevel(), nil)
+			g.playerCoords.PlayerY = tc.playerY
+
+			if tc.wantState != doorClosed {
+				g.OpenDoor(2.5, 2.5)
+			}
+
+			for _, elapsed := range tc.updates {
+				g.updateDoors(elapsed)
+			}
+
+			d, _ := g.findDoor(2.5, 2.5)
+			if d.state != tc.wantState {
+				t.Errorf("Expected state %d, got %d", tc.wantState, d.state)
+			}
+
+			state, _ := g.GetDoorState(2.5, 2.5)
+			if state.OpenFraction < tc.wantFraction-0.000001 || state.OpenFraction > tc.wantFraction+0.000001 {
+				t.Errorf("Expected open fraction %f, got %f", tc.wantFraction, state.OpenFraction)
+			}
+
+			if got, _ := g.CheckWallCollision(2.5, 2.5); got != tc.wantCollision {
+				t.Errorf("Expected collision %t, got %t", tc.wantCollision, got)
+			}
+		})
+	}
+}
+
+func Test_GameWalkIntoDoor(t *testing.T) {
+	inputHandler := input.NewInputHandler()
+	inputHandler.HandleKeyEvent("w", true)
+
+	levelData := newDoorTestLevel()
+	levelData.PlayerAngle = 270.0
+	g := NewGame(testGameConfig, levelData, inputHandler)
+
+	// The door is out of reach at first, the player walks up to it and it opens.
+	g.RunTicks(1)
+	if state, _ := g.GetDoorState(2.5, 2.5); state.OpenFraction != 0.0 {
+		t.Errorf("Expected the door to still be closed, got open fraction %f", state.OpenFraction)
+	}
+
+	// Two seconds is plenty to reach the door, wait for it to open and walk through.
+	g.RunTicks(2 * testGameConfig.GetTickRate())
+	if got := g.GetPlayerCoords(); got.PlayerY <= 3.0 {
+		t.Errorf("Expected the player past the door, got %+v", got)
+	}
+}
@@ -1,66 +1,180 @@
 package game
 
 import (
+	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
 	"github.com/rebay1982/redcaster/internal/input"
 	"math"
+	"time"
+)
+
+const (
+	// Distance beyond the player's radius, in map units, checked for doors to open.
+	COLLISION_PROBE_DISTANCE = 0.1
+
+	// Movement speed multiplier while the run action is held.
+	RUN_SPEED_MULTIPLIER = 2.0
+
+	// Upper bound of simulated time per call to Advance. Prevents the simulation from never catching up after a stall.
+	MAX_ADVANCE_DURATION = 250 * time.Millisecond
 )
 
 type Game struct {
+	config       config.GameConfiguration
 	playerCoords data.PlayerCoordData
 	gameMap      [][]int
+	doors        map[doorCell]*door
+	doorList     []*door // Same doors, in level order.
+	sprites      []data.SpriteData
 	inputHandler *input.InputHandler
+
+	mapMode data.MapMode
+	// Whether the map action was held on the previous tick, the map mode changes once per press.
+	mapActionHeld bool
+
+	// Simulated time not yet consumed by a tick.
+	accumulator time.Duration
+
+	tick      uint64
+	snapshots *snapshotBuffer
 }
 
-func NewGame(levelData data.LevelData, inputHandler *input.InputHandler) Game {
-	return Game{
+func NewGame(config config.GameConfiguration, levelData data.LevelData, inputHandler *input.InputHandler) Game {
+	doors := newDoors(levelData)
+	doorList := make([]*door, 0, len(levelData.Doors))
+	for _, doorData := range levelData.Doors {
+		doorList = append(doorList, doors[doorCell{x: doorData.X, y: doorData.Y}])
+	}
+
+	g := Game{
+		config:       config,
 		playerCoords: levelData.GetPlayerCoordData(),
 		gameMap:      levelData.GetMapData(),
+		doors:        doors,
+		doorList:     doorList,
+		sprites:      levelData.Sprites,
 		inputHandler: inputHandler,
+		snapshots:    &snapshotBuffer{},
 	}
+
+	// Publish the initial state so there's always a snapshot to render.
+	g.publishSnapshot()
+
+	return g
 }
 
-func (g *Game) Update() {
-	inputVector := g.inputHandler.GetInputVector()
+// Advance adds elapsed real time to the simulation and runs as many fixed ticks as it covers. The leftover time is kept
+// for the next call. Returns the time until the next tick is due.
+func (g *Game) Advance(elapsed time.Duration) time.Duration {
+	tickDuration := g.config.GetTickDuration()
 
-	if inputVector.PlayerRight {
-		g.playerCoords.PlayerAngle -= 0.3
+	g.accumulator += min(elapsed, MAX_ADVANCE_DURATION)
+	for g.accumulator >= tickDuration {
+		g.Update(tickDuration.Seconds())
+		g.accumulator -= tickDuration
+	}
 
-		if g.playerCoords.PlayerAngle < 0.0 {
-			g.playerCoords.PlayerAngle += 360.0
-		}
+	return tickDuration - g.accumulator
+}
+
+// RunTicks runs the simulation for a number of fixed ticks, regardless of real time.
+func (g *Game) RunTicks(ticks int) {
+	dt := g.config.GetTickDuration().Seconds()
+	for i := 0; i < ticks; i++ {
+		g.Update(dt)
 	}
+}
 
-	if inputVector.PlayerLeft {
-		g.playerCoords.PlayerAngle += 0.3
+// Update advances the game by dt seconds and publishes a new world snapshot. Not safe to call concurrently with itself.
+func (g *Game) Update(dt float64) {
+	g.updateDoors(dt)
 
-		if g.playerCoords.PlayerAngle > 360.0 {
-			g.playerCoords.PlayerAngle -= 360.0
-		}
+	inputVector := g.inputHandler.PollInputVector()
+
+	mapActionHeld := inputVector.IsActive(input.ACTION_MAP)
+	if mapActionHeld && !g.mapActionHeld {
+		g.mapMode = g.mapMode.Next()
 	}
+	g.mapActionHeld = mapActionHeld
 
+	turn := -inputVector.MouseDeltaX * g.config.GetMouseSensitivity()
+	if inputVector.IsActive(input.ACTION_TURN_RIGHT) {
+		turn -= g.config.GetTurnSpeed() * dt
+	}
+	if inputVector.IsActive(input.ACTION_TURN_LEFT) {
+		turn += g.config.GetTurnSpeed() * dt
+	}
+
+	g.playerCoords.PlayerAngle = math.Mod(g.playerCoords.PlayerAngle+turn, 360.0)
+	if g.playerCoords.PlayerAngle < 0.0 {
+		g.playerCoords.PlayerAngle += 360.0
+	}
+
+	// Facing direction, Y grows southward.
 	pRad := g.playerCoords.PlayerAngle * math.Pi / 180.0
-	deltaX := 0.01 * math.Cos(pRad)
-	deltaY := 0.01 * math.Sin(pRad)
-	colX := deltaX * 10
-	colY := deltaY * 10
-	if inputVector.PlayerForward {
-		if hit, _ := g.CheckWallCollision(g.playerCoords.PlayerX+colX, g.playerCoords.PlayerY-colY); !hit {
-			g.playerCoords.PlayerX += deltaX
-			g.playerCoords.PlayerY -= deltaY
-		}
+	facingX := math.Cos(pRad)
+	facingY := -math.Sin(pRad)
+
+	// Walking into a door, or using it, opens it.
+	if inputVector.IsActive(input.ACTION_FORWARD) || inputVector.IsActive(input.ACTION_USE) {
+		reach := g.config.GetPlayerRadius() + COLLISION_PROBE_DISTANCE
+		g.OpenDoor(g.playerCoords.PlayerX+reach*facingX, g.playerCoords.PlayerY+reach*facingY)
+	}
+
+	moveX, moveY := 0.0, 0.0
+	if inputVector.IsActive(input.ACTION_FORWARD) {
+		moveX += facingX
+		moveY += facingY
+	}
+	if inputVector.IsActive(input.ACTION_BACKWARD) {
+		moveX -= facingX
+		moveY -= facingY
+	}
+	// Strafing moves perpendicular to the facing direction, right is a quarter turn clockwise.
+	if inputVector.IsActive(input.ACTION_STRAFE_LEFT) {
+		moveX += facingY
+		moveY -= facingX
+	}
+	if inputVector.IsActive(input.ACTION_STRAFE_RIGHT) {
+		moveX -= facingY
+		moveY += facingX
 	}
 
-	if inputVector.PlayerBackward {
-		if hit, _ := g.CheckWallCollision(g.playerCoords.PlayerX-colX, g.playerCoords.PlayerY+colY); !hit {
-			g.playerCoords.PlayerX -= deltaX
-			g.playerCoords.PlayerY += deltaY
+	// Moving diagonally isn't any faster.
+	if moveLength := math.Hypot(moveX, moveY); moveLength > 0.000001 {
+		moveX /= moveLength
+		moveY /= moveLength
+
+		speed := g.config.GetMoveSpeed() * dt
+		if inputVector.IsActive(input.ACTION_RUN) {
+			speed *= RUN_SPEED_MULTIPLIER
 		}
+
+		g.playerCoords.PlayerX, g.playerCoords.PlayerY = g.MoveActor(g.playerCoords.PlayerX, g.playerCoords.PlayerY,
+			g.config.GetPlayerRadius(), speed*moveX, speed*moveY)
 	}
+
+	g.publishSnapshot()
+}
+
+// CheckWallCollision returns true if there's a wall at the given coordinates alot with the wall's type ID. Doors are
+// considered walls while they're mostly closed, their texture ID is returned as the wall type. Door states change as
+// the game updates, use CheckMapCollision from outside of the update loop.
+func (g *Game) CheckWallCollision(x, y float64) (bool, int) {
+	if collision, wall := g.CheckMapCollision(x, y); collision {
+		return collision, wall
+	}
+
+	if d, ok := g.doors[doorCell{x: int(x), y: int(y)}]; ok && d.isSolid() {
+		return true, d.TextureId
+	}
+
+	return false, 0
 }
 
-// CheckWallCollision returns true if there's a wall at the given coordinates alot with the wall's type ID.
-func (g Game) CheckWallCollision(x, y float64) (bool, int) {
+// CheckMapCollision returns true if there's a wall in the level's map at the given coordinates along with the wall's
+// type ID. Doors are ignored. The map never changes, so it's safe to call while the game is updating.
+func (g *Game) CheckMapCollision(x, y float64) (bool, int) {
 	ix := int(x)
 	iy := int(y)
 
@@ -85,6 +199,7 @@ func (g Game) CheckWallCollision(x, y float64) (bool, int) {
 	}
 }
 
+// GetPlayerCoords returns the player's live coordinates. Use GetWorldSnapshot from outside of the update loop.
 func (g Game) GetPlayerCoords() data.PlayerCoordData {
 	return g.playerCoords
 }
@@ -1,11 +1,18 @@
 package game
 
 import (
+	"bytes"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/input"
 )
 
+var testGameConfig = config.NewGameConfiguration(config.TICK_RATE, config.MOVE_SPEED, config.TURN_SPEED)
+
 func Test_RendererCheckWallCollision(t *testing.T) {
 	levelData := data.LevelData{
 		Map: [][]int{
@@ -27,7 +34,7 @@ func Test_RendererCheckWallCollision(t *testing.T) {
 			{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
 		},
 	}
-	g := NewGame(levelData, nil)
+	g := NewGame(testGameConfig, levelData, nil)
 
 	testCases := []struct {
 		name      string
@@ -139,6 +146,300 @@ func Test_RendererCheckWallCollision(t *testing.T) {
 			if gotWType != tc.wantWType {
 				t.Errorf("Expected wall type %d, got %d", tc.wantWType, gotWType)
 			}
+
+			// Without doors, the map alone gives the same answer.
+			gotMap, gotMapWType := g.CheckMapCollision(tc.x, tc.y)
+			if gotMap != tc.want || gotMapWType != tc.wantWType {
+				t.Errorf("Expected map collision (%t, %d), got (%t, %d)", tc.want, tc.wantWType, gotMap, gotMapWType)
+			}
+		})
+	}
+}
+
+func Test_GameRunTicks(t *testing.T) {
+	levelData := data.LevelData{
+		Map: [][]int{
+			{1, 1, 1, 1, 1, 1, 1, 1},
+			{1, 0, 0, 0, 0, 0, 0, 1},
+			{1, 0, 0, 0, 0, 0, 0, 1},
+			{1, 0, 0, 0, 0, 0, 0, 1},
+			{1, 1, 1, 1, 1, 1, 1, 1},
+		},
+		PlayerCoordData: data.PlayerCoordData{
+			PlayerX:     1.5,
+			PlayerY:     2.5,
+			PlayerAngle: 0.0,
+		},
+	}
+
+	// 10 ticks per second, 1 unit and 90 degrees per second makes for easy numbers.
+	gameConfig := config.NewGameConfiguration(10, 1.0, 90.0)
+
+	testCases := []struct {
+		name        string
+		keys        []input.Key
+		actions     []input.Action
+		mouseDeltaX float64
+		start       *data.PlayerCoordData
+		ticks       int
+		wantCoords  data.PlayerCoordData
+	}{
+		{
+			name:  "idle",
+			keys:  []input.Key{},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     2.5,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:  "forward_one_second",
+			keys:  []input.Key{"w"},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     2.5,
+				PlayerY:     2.5,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:  "turn_left_one_second",
+			keys:  []input.Key{"a"},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     2.5,
+				PlayerAngle: 90.0,
+			},
+		},
+		{
+			name:  "turn_right_half_second",
+			keys:  []input.Key{"d"},
+			ticks: 5,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     2.5,
+				PlayerAngle: 315.0,
+			},
+		},
+		{
+			name:  "strafe_left_one_second",
+			keys:  []input.Key{"q"},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     1.5,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:  "strafe_right_one_second",
+			keys:  []input.Key{"e"},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     3.5,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:  "forward_and_strafe_diagonal",
+			keys:  []input.Key{"w", "q"},
+			ticks: 10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5 + math.Sqrt2/2.0,
+				PlayerY:     2.5 - math.Sqrt2/2.0,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:  "slide_along_wall",
+			keys:  []input.Key{"w"},
+			ticks: 10,
+			start: &data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     2.5,
+				PlayerAngle: 135.0,
+			},
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.0 + config.PLAYER_RADIUS,
+				PlayerY:     2.5 - math.Sqrt2/2.0,
+				PlayerAngle: 135.0,
+			},
+		},
+		{
+			name:    "run_forward_half_second",
+			keys:    []input.Key{"w"},
+			actions: []input.Action{input.ACTION_RUN},
+			ticks:   5,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     2.5,
+				PlayerY:     2.5,
+				PlayerAngle: 0.0,
+			},
+		},
+		{
+			name:        "mouse_look_right",
+			mouseDeltaX: 100.0,
+			ticks:       10,
+			wantCoords: data.PlayerCoordData{
+				PlayerX:     1.5,
+				PlayerY:     2.5,
+				PlayerAngle: 350.0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inputHandler := input.NewInputHandler()
+			for _, key := range tc.keys {
+				inputHandler.HandleKeyEvent(key, true)
+			}
+			for _, action := range tc.actions {
+				inputHandler.SetAction(action, true)
+			}
+			inputHandler.HandleMouseMove(tc.mouseDeltaX)
+
+			level := levelData
+			if tc.start != nil {
+				level.PlayerCoordData = *tc.start
+			}
+
+			g := NewGame(gameConfig, level, inputHandler)
+			g.RunTicks(tc.ticks)
+
+			got := g.GetPlayerCoords()
+			if math.Abs(got.PlayerX-tc.wantCoords.PlayerX) > 0.000001 ||
+				math.Abs(got.PlayerY-tc.wantCoords.PlayerY) > 0.000001 ||
+				math.Abs(got.PlayerAngle-tc.wantCoords.PlayerAngle) > 0.000001 {
+				t.Errorf("Expected %+v, got %+v", tc.wantCoords, got)
+			}
 		})
 	}
 }
+
+func Test_GameAdvance(t *testing.T) {
+	gameConfig := config.NewGameConfiguration(10, 1.0, 90.0)
+	levelData := data.LevelData{
+		Map: [][]int{
+			{0},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		elapsed           []time.Duration
+		wantTicks         uint64
+		wantUntilNextTick time.Duration
+	}{
+		{
+			name:              "less_than_a_tick",
+			elapsed:           []time.Duration{50 * time.Millisecond},
+			wantTicks:         0,
+			wantUntilNextTick: 50 * time.Millisecond,
+		},
+		{
+			name:              "accumulated_ticks",
+			elapsed:           []time.Duration{50 * time.Millisecond, 60 * time.Millisecond, 150 * time.Millisecond},
+			wantTicks:         2,
+			wantUntilNextTick: 40 * time.Millisecond,
+		},
+		{
+			name:              "stall_is_capped",
+			elapsed:           []time.Duration{10 * time.Second},
+			wantTicks:         2,
+			wantUntilNextTick: 50 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGame(gameConfig, levelData, input.NewInputHandler())
+			startTick := g.tick
+
+			var untilNextTick time.Duration
+			for _, elapsed := range tc.elapsed {
+				untilNextTick = g.Advance(elapsed)
+			}
+
+			if got := g.tick - startTick; got != tc.wantTicks {
+				t.Errorf("Expected %d ticks, got %d", tc.wantTicks, got)
+			}
+
+			if untilNextTick != tc.wantUntilNextTick {
+				t.Errorf("Expected next tick in %v, got %v", tc.wantUntilNextTick, untilNextTick)
+			}
+		})
+	}
+}
+
+func Test_GameReplay(t *testing.T) {
+	levelData := newDoorTestLevel()
+	levelData.PlayerCoordData = data.PlayerCoordData{PlayerX: 2.5, PlayerY: 1.5, PlayerAngle: 270.0}
+
+	// Input changes by tick: walk through the door, look around with the mouse and the keys, then run into a wall.
+	script := map[int]func(i *input.InputHandler){
+		0:   func(i *input.InputHandler) { i.SetAction(input.ACTION_FORWARD, true) },
+		40:  func(i *input.InputHandler) { i.HandleMouseMove(30.0) },
+		60:  func(i *input.InputHandler) { i.SetAction(input.ACTION_TURN_LEFT, true) },
+		90:  func(i *input.InputHandler) { i.SetAction(input.ACTION_TURN_LEFT, false) },
+		91:  func(i *input.InputHandler) { i.SetAction(input.ACTION_RUN, true) },
+		100: func(i *input.InputHandler) { i.SetAction(input.ACTION_STRAFE_RIGHT, true) },
+		130: func(i *input.InputHandler) { i.SetAction(input.ACTION_FORWARD, false) },
+	}
+	const ticks = 200
+
+	type tickState struct {
+		player data.PlayerCoordData
+		door   data.DoorState
+	}
+
+	run := func(i *input.InputHandler, live bool) []tickState {
+		g := NewGame(testGameConfig, levelData, i)
+
+		states := []tickState{}
+		for tick := 0; tick < ticks; tick++ {
+			if change, ok := script[tick]; ok && live {
+				change(i)
+			}
+			g.RunTicks(1)
+
+			door, _ := g.GetDoorState(2.5, 2.5)
+			states = append(states, tickState{player: g.GetPlayerCoords(), door: door})
+		}
+
+		return states
+	}
+
+	recorded := &bytes.Buffer{}
+	liveInput := input.NewInputHandler()
+	if err := liveInput.StartRecording(recorded, input.RecordingHeader{TickRate: testGameConfig.GetTickRate()}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	want := run(liveInput, true)
+	if err := liveInput.StopRecording(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	recording, err := input.DecodeRecording(recorded)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	replayInput := input.NewInputHandler()
+	replayInput.StartReplay(recording)
+	got := run(replayInput, false)
+
+	// The replay must follow the exact same path, not an approximation of it.
+	for tick := range want {
+		if got[tick] != want[tick] {
+			t.Fatalf("Replay diverged at tick %d, expected %+v, got %+v", tick, want[tick], got[tick])
+		}
+	}
+
+	if end := want[ticks-1].player; end.PlayerY < 3.0 {
+		t.Errorf("Expected the scripted run to move the player through the door, ended at %+v", end)
+	}
+}
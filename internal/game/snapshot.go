@@ -0,0 +1,81 @@
+package game
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+type snapshotPair struct {
+	previous data.WorldSnapshot
+	current  data.WorldSnapshot
+}
+
+// snapshotBuffer holds the last two published world snapshots. It is safe to publish and read concurrently.
+type snapshotBuffer struct {
+	latest atomic.Pointer[snapshotPair]
+}
+
+func (sb *snapshotBuffer) publish(snapshot data.WorldSnapshot) {
+	pair := &snapshotPair{
+		previous: snapshot,
+		current:  snapshot,
+	}
+
+	if latest := sb.latest.Load(); latest != nil {
+		pair.previous = latest.current
+	}
+
+	sb.latest.Store(pair)
+}
+
+// setMapMode changes the map mode of the latest snapshots.
+func (sb *snapshotBuffer) setMapMode(mode data.MapMode) {
+	pair := *sb.latest.Load()
+	pair.previous.MapMode = mode
+	pair.current.MapMode = mode
+
+	sb.latest.Store(&pair)
+}
+
+// publishSnapshot publishes the current state of the world. Doors and sprites are copied so the snapshot never
+// changes once published.
+func (g *Game) publishSnapshot() {
+	doors := make([]data.DoorState, 0, len(g.doors))
+	for _, d := range g.doorList {
+		doors = append(doors, d.getState())
+	}
+
+	sprites := make([]data.SpriteData, len(g.sprites))
+	copy(sprites, g.sprites)
+
+	// The first snapshot is published before any tick ran.
+	elapsed := time.Duration(g.tick) * g.config.GetTickDuration()
+
+	g.tick++
+	snapshot := data.NewWorldSnapshot(g.tick, time.Now(), elapsed, g.playerCoords, doors, sprites)
+	snapshot.MapMode = g.mapMode
+	g.snapshots.publish(snapshot)
+}
+
+// SetMapMode changes the map overlay. The latest snapshot is updated right away, so the change shows even if the game
+// isn't running. Not safe to call concurrently with Update.
+func (g *Game) SetMapMode(mode data.MapMode) {
+	g.mapMode = mode
+	g.snapshots.setMapMode(mode)
+}
+
+// GetWorldSnapshot returns a consistent view of the world, interpolated between the last two published snapshots
+// based on the time elapsed since the last one, relative to a tick. Safe to call while the game is updating.
+func (g *Game) GetWorldSnapshot() data.WorldSnapshot {
+	pair := g.snapshots.latest.Load()
+
+	tickDuration := g.config.GetTickDuration()
+	if pair.previous.Tick == pair.current.Tick {
+		return pair.current
+	}
+
+	alpha := float64(time.Since(pair.current.Time)) / float64(tickDuration)
+	return data.InterpolateWorldSnapshots(pair.previous, pair.current, alpha)
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/input"
+)
+
+func Test_GamePublishSnapshot(t *testing.T) {
+	levelData := newDoorTestLevel()
+	levelData.Sprites = []data.SpriteData{{X: 1.5, Y: 1.5, TextureId: 1, Scale: 1.0}}
+	g := NewGame(testGameConfig, levelData, input.NewInputHandler())
+
+	initial := g.snapshots.latest.Load()
+	if initial.current.Tick != 1 || initial.previous.Tick != 1 {
+		t.Fatalf("Expected initial snapshots for tick 1, got %d and %d", initial.previous.Tick, initial.current.Tick)
+	}
+
+	g.OpenDoor(2.5, 2.5)
+	g.playerCoords.PlayerX = 3.0
+	g.RunTicks(1)
+
+	latest := g.snapshots.latest.Load()
+	if latest.previous.Tick != 1 || latest.current.Tick != 2 {
+		t.Errorf("Expected snapshots for ticks 1 and 2, got %d and %d", latest.previous.Tick, latest.current.Tick)
+	}
+
+	if latest.current.Player.PlayerX != 3.0 {
+		t.Errorf("Expected player X of 3.0, got %f", latest.current.Player.PlayerX)
+	}
+
+	if len(latest.current.Doors) != 2 || latest.current.Doors[0].X != 2 || latest.current.Doors[1].X != 1 {
+		t.Errorf("Expected doors in level order, got %v", latest.current.Doors)
+	}
+
+	if len(latest.current.Sprites) != 1 {
+		t.Errorf("Expected 1 sprite, got %d", len(latest.current.Sprites))
+	}
+
+	// Published snapshots must not change with the game.
+	g.playerCoords.PlayerX = 4.0
+	if latest.current.Player.PlayerX != 3.0 {
+		t.Errorf("Published snapshot changed, got player X of %f", latest.current.Player.PlayerX)
+	}
+}
+
+func Test_GameSnapshotConcurrentAccess(t *testing.T) {
+	g := NewGame(testGameConfig, newDoorTestLevel(), input.NewInputHandler())
+	g.OpenDoor(2.5, 2.5)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			g.RunTicks(1)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		snapshot := g.GetWorldSnapshot()
+		if _, ok := snapshot.GetDoorState(2.5, 2.5); !ok {
+			t.Fatalf("Expected a door in snapshot %d", snapshot.Tick)
+		}
+	}
+
+	wg.Wait()
+}
+
+func Test_GameMapMode(t *testing.T) {
+	inputHandler := input.NewInputHandler()
+	g := NewGame(testGameConfig, newDoorTestLevel(), inputHandler)
+
+	mapMode := func() data.MapMode {
+		return g.snapshots.latest.Load().current.MapMode
+	}
+
+	// Holding the map key only changes the mode once.
+	inputHandler.HandleKeyEvent("tab", true)
+	g.RunTicks(3)
+	if got := mapMode(); got != data.MAP_MODE_MINIMAP {
+		t.Errorf("Expected the minimap after the first press, got %v", got)
+	}
+
+	inputHandler.HandleKeyEvent("tab", false)
+	g.RunTicks(1)
+	inputHandler.HandleKeyEvent("tab", true)
+	g.RunTicks(1)
+	if got := mapMode(); got != data.MAP_MODE_AUTOMAP {
+		t.Errorf("Expected the automap after the second press, got %v", got)
+	}
+
+	// Setting the mode shows right away, without running the game.
+	g.SetMapMode(data.MAP_MODE_OFF)
+	if got := mapMode(); got != data.MAP_MODE_OFF {
+		t.Errorf("Expected the map to be off, got %v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package hud
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+const (
+	// Frame buffer height, in pixels, at which text is drawn at scale 1. Taller frame buffers scale the text up.
+	HUD_REFERENCE_HEIGHT = 240
+	// Distance, in font pixels, between the HUD and the edges of the screen.
+	HUD_MARGIN = 4
+
+	// How long messages stay on screen, and how many are shown at once.
+	MESSAGE_DURATION = 3 * time.Second
+	MAX_MESSAGES     = 4
+
+	HUD_TEXT_COLOR    = 0xFFFFFFFF
+	HUD_DEBUG_COLOR   = 0xFF00FF00
+	HUD_MESSAGE_COLOR = 0xFF00FFFF
+
+	// Shown until the game keeps track of the player's health and ammo.
+	PLACEHOLDER_HEALTH = 100
+	PLACEHOLDER_AMMO   = 50
+)
+
+// Status is what the HUD shows for a frame. The elapsed time is the game clock, messages expire relative to it. A
+// frame rate of 0 hides the FPS counter.
+type Status struct {
+	Player  data.PlayerCoordData
+	Elapsed time.Duration
+	Fps     float64
+	Health  int
+	Ammo    int
+}
+
+type message struct {
+	text    string
+	expires time.Duration
+}
+
+// HUD draws the heads-up display over a rendered frame. It only needs the frame buffer, it doesn't depend on how the
+// world was rendered.
+type HUD struct {
+	config config.RenderConfiguration
+
+	// The main goroutine shows messages and draws frames. The mutex guards the messages and the clock, so messages can
+	// also be shown from other goroutines while a frame is drawn.
+	mu       sync.Mutex
+	messages []message
+	// Game clock of the last frame drawn.
+	elapsed time.Duration
+}
+
+func NewHUD(config config.RenderConfiguration) *HUD {
+	return &HUD{
+		config: config,
+	}
+}
+
+func (h *HUD) Reconfigure(config config.RenderConfiguration) {
+	h.config = config
+}
+
+// ShowMessage shows a message for a while, from the last frame drawn. The oldest message is dropped when there are too
+// many. Safe to call while the HUD is drawing.
+func (h *HUD) ShowMessage(text string, duration time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.messages = append(h.messages, message{text: text, expires: h.elapsed + duration})
+	if len(h.messages) > MAX_MESSAGES {
+		h.messages = h.messages[len(h.messages)-MAX_MESSAGES:]
+	}
+}
+
+// activeMessages moves the HUD's clock to the frame's and returns the messages that haven't expired yet.
+func (h *HUD) activeMessages(elapsed time.Duration) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.elapsed = elapsed
+
+	active := h.messages[:0]
+	texts := []string{}
+	for _, m := range h.messages {
+		if m.expires > elapsed {
+			active = append(active, m)
+			texts = append(texts, m.text)
+		}
+	}
+	h.messages = active
+
+	return texts
+}
+
+// Draw draws the HUD over a frame: debug information in the top left corner, health and ammo at the bottom and
+// messages towards the top.
+func (h *HUD) Draw(frameBuffer []uint8, status Status) {
+	canvas := Canvas{
+		FrameBuffer: frameBuffer,
+		Width:       h.config.GetFbWidth(),
+		Height:      h.config.GetFbHeight(),
+	}
+
+	scale := max(canvas.Height/HUD_REFERENCE_HEIGHT, 1)
+	margin := HUD_MARGIN * scale
+
+	debug := fmt.Sprintf("X %.2f  Y %.2f  A %.0f", status.Player.PlayerX, status.Player.PlayerY,
+		status.Player.PlayerAngle)
+	if status.Fps > 0.0 {
+		debug = fmt.Sprintf("FPS %.1f\n%s", status.Fps, debug)
+	}
+	canvas.DrawText(margin, margin, debug, TextStyle{Color: HUD_DEBUG_COLOR, Scale: scale, Shadow: true})
+
+	// Health and ammo are drawn twice as large.
+	statusStyle := TextStyle{Color: HUD_TEXT_COLOR, Scale: scale * 2, Shadow: true}
+	_, statusHeight := MeasureText("", statusStyle.Scale)
+	bottom := canvas.Height - margin - statusHeight
+	canvas.DrawText(margin, bottom, fmt.Sprintf("HEALTH %d", status.Health), statusStyle)
+
+	statusStyle.Align = ALIGN_RIGHT
+	canvas.DrawText(canvas.Width-margin, bottom, fmt.Sprintf("AMMO %d", status.Ammo), statusStyle)
+
+	if messages := h.activeMessages(status.Elapsed); len(messages) > 0 {
+		canvas.DrawText(canvas.Width/2, canvas.Height/4, strings.Join(messages, "\n"),
+			TextStyle{Color: HUD_MESSAGE_COLOR, Scale: scale, Align: ALIGN_CENTER, Shadow: true})
+	}
+}
@@ -0,0 +1,93 @@
+package hud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rebay1982/redcaster/internal/config"
+)
+
+func Test_HUDMessages(t *testing.T) {
+	h := NewHUD(config.NewRenderConfiguration(320, 240, 60.0, false))
+
+	h.ShowMessage("first", time.Second)
+	h.activeMessages(500 * time.Millisecond)
+	h.ShowMessage("second", time.Second)
+
+	testCases := []struct {
+		name     string
+		elapsed  time.Duration
+		expected []string
+	}{
+		{
+			name:     "both_shown",
+			elapsed:  900 * time.Millisecond,
+			expected: []string{"first", "second"},
+		},
+		{
+			name:     "first_expired",
+			elapsed:  time.Second,
+			expected: []string{"second"},
+		},
+		{
+			name:     "all_expired",
+			elapsed:  2 * time.Second,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, h.activeMessages(tc.elapsed)); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_HUDMaxMessages(t *testing.T) {
+	h := NewHUD(config.NewRenderConfiguration(320, 240, 60.0, false))
+	for _, text := range []string{"1", "2", "3", "4", "5"} {
+		h.ShowMessage(text, time.Second)
+	}
+
+	expected := []string{"2", "3", "4", "5"}
+	if diff := cmp.Diff(expected, h.activeMessages(0)); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
+
+func Test_HUDDraw(t *testing.T) {
+	canvas := newTestCanvas(320, 240)
+	h := NewHUD(config.NewRenderConfiguration(canvas.Width, canvas.Height, 60.0, true))
+	h.ShowMessage("Hello", time.Second)
+
+	h.Draw(canvas.FrameBuffer, Status{Fps: 60.0, Health: PLACEHOLDER_HEALTH, Ammo: PLACEHOLDER_AMMO})
+
+	// Count the pixels of each colour in the screen's quarters.
+	countPixels := func(color uint32, left, top int) int {
+		count := 0
+		for y := top; y < top+canvas.Height/2; y++ {
+			for x := left; x < left+canvas.Width/2; x++ {
+				if canvas.pixel(x, y) == color {
+					count++
+				}
+			}
+		}
+		return count
+	}
+
+	if countPixels(HUD_DEBUG_COLOR, 0, 0) == 0 {
+		t.Errorf("Expected debug information in the top left corner")
+	}
+
+	if countPixels(HUD_TEXT_COLOR, 0, canvas.Height/2) == 0 || countPixels(HUD_TEXT_COLOR, canvas.Width/2,
+		canvas.Height/2) == 0 {
+		t.Errorf("Expected health and ammo at the bottom of the screen")
+	}
+
+	if countPixels(HUD_MESSAGE_COLOR, 0, 0)+countPixels(HUD_MESSAGE_COLOR, canvas.Width/2, 0) == 0 {
+		t.Errorf("Expected the message at the top of the screen")
+	}
+}
@@ -0,0 +1,105 @@
+package hud
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// Alignment selects which side of the text the drawing position is.
+type Alignment int
+
+const (
+	ALIGN_LEFT Alignment = iota
+	ALIGN_CENTER
+	ALIGN_RIGHT
+)
+
+// TextStyle describes how text is drawn. Colours are in the frame buffer's format, the scale is the size of a font
+// pixel in screen pixels. Shadowed text gets a black drop shadow, to stay readable over any background.
+type TextStyle struct {
+	Color  uint32
+	Scale  int
+	Align  Alignment
+	Shadow bool
+}
+
+// Canvas is a frame buffer text is drawn into, RGBA pixels with rows from the bottom of the screen up.
+type Canvas struct {
+	FrameBuffer []uint8
+	Width       int
+	Height      int
+}
+
+// MeasureText returns the width and height, in pixels, of text drawn at the given scale. Lines are split on line feeds.
+func MeasureText(text string, scale int) (int, int) {
+	lines := strings.Split(text, "\n")
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, measureLine(line, scale))
+	}
+
+	return width, len(lines)*GLYPH_HEIGHT*scale + (len(lines)-1)*LINE_SPACING*scale
+}
+
+func measureLine(line string, scale int) int {
+	glyphs := len([]rune(line))
+	if glyphs == 0 {
+		return 0
+	}
+
+	return (glyphs*(GLYPH_WIDTH+GLYPH_SPACING) - GLYPH_SPACING) * scale
+}
+
+// DrawText draws text with its top at y, in pixels from the top of the screen. Depending on the alignment, x is the
+// left, centre or right of each line. Text going past the edges of the canvas is clipped.
+func (c Canvas) DrawText(x, y int, text string, style TextStyle) {
+	scale := max(style.Scale, 1)
+
+	if style.Shadow {
+		shadowStyle := style
+		shadowStyle.Color = 0xFF000000
+		shadowStyle.Shadow = false
+		c.DrawText(x+scale, y+scale, text, shadowStyle)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		left := x
+		switch style.Align {
+		case ALIGN_CENTER:
+			left -= measureLine(line, scale) / 2
+		case ALIGN_RIGHT:
+			left -= measureLine(line, scale)
+		}
+
+		for _, r := range line {
+			c.drawGlyph(left, y, lookupGlyph(r), style.Color, scale)
+			left += (GLYPH_WIDTH + GLYPH_SPACING) * scale
+		}
+
+		y += (GLYPH_HEIGHT + LINE_SPACING) * scale
+	}
+}
+
+func (c Canvas) drawGlyph(left, top int, g glyph, color uint32, scale int) {
+	for row := 0; row < GLYPH_HEIGHT; row++ {
+		for col := 0; col < GLYPH_WIDTH; col++ {
+			if g[row]&(1<<(GLYPH_WIDTH-1-col)) == 0 {
+				continue
+			}
+
+			c.fillRect(left+col*scale, top+row*scale, scale, scale, color)
+		}
+	}
+}
+
+// fillRect fills a rectangle, clipped to the canvas. The position is the top left corner, from the top left of the
+// screen.
+func (c Canvas) fillRect(left, top, width, height int, color uint32) {
+	for y := max(top, 0); y < min(top+height, c.Height); y++ {
+		for x := max(left, 0); x < min(left+width, c.Width); x++ {
+			fbIndex := (x + (c.Height-1-y)*c.Width) << 2
+			*(*uint32)(unsafe.Pointer(&c.FrameBuffer[fbIndex])) = color
+		}
+	}
+}
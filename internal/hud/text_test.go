@@ -0,0 +1,175 @@
+package hud
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// newTestCanvas returns a black canvas.
+func newTestCanvas(width, height int) Canvas {
+	canvas := Canvas{
+		FrameBuffer: make([]uint8, width*height*4),
+		Width:       width,
+		Height:      height,
+	}
+	canvas.fillRect(0, 0, width, height, 0xFF000000)
+
+	return canvas
+}
+
+func (c Canvas) pixel(x, y int) uint32 {
+	fbIndex := (x + (c.Height-1-y)*c.Width) << 2
+	return *(*uint32)(unsafe.Pointer(&c.FrameBuffer[fbIndex]))
+}
+
+// render draws the canvas as text, # for pixels of the given colour and . for anything else, top row first.
+func (c Canvas) render(color uint32) string {
+	rows := make([]string, 0, c.Height)
+	for y := 0; y < c.Height; y++ {
+		row := strings.Builder{}
+		for x := 0; x < c.Width; x++ {
+			if c.pixel(x, y) == color {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func Test_FontGlyphs(t *testing.T) {
+	for r, rows := range fontGlyphs {
+		for y, row := range rows {
+			if len(row) != GLYPH_WIDTH || strings.Trim(row, ".#") != "" {
+				t.Errorf("Glyph %q row %d is %q, expected %d pixels of . or #", r, y, row, GLYPH_WIDTH)
+			}
+		}
+	}
+
+	if lookupGlyph('a') != font['A'] {
+		t.Errorf("Expected lowercase letters to be drawn as uppercase")
+	}
+
+	if lookupGlyph('é') != font['?'] {
+		t.Errorf("Expected unknown runes to be drawn as a question mark")
+	}
+}
+
+func Test_MeasureText(t *testing.T) {
+	testCases := []struct {
+		name           string
+		text           string
+		scale          int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{
+			name:           "empty",
+			text:           "",
+			scale:          1,
+			expectedWidth:  0,
+			expectedHeight: 7,
+		},
+		{
+			name:           "single_line",
+			text:           "FPS",
+			scale:          1,
+			expectedWidth:  17,
+			expectedHeight: 7,
+		},
+		{
+			name:           "scaled_lines",
+			text:           "AB\nCDEF",
+			scale:          2,
+			expectedWidth:  46,
+			expectedHeight: 32,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			width, height := MeasureText(tc.text, tc.scale)
+
+			if width != tc.expectedWidth || height != tc.expectedHeight {
+				t.Errorf("Expected %dx%d, got %dx%d", tc.expectedWidth, tc.expectedHeight, width, height)
+			}
+		})
+	}
+}
+
+func Test_CanvasDrawText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		x, y     int
+		text     string
+		style    TextStyle
+		expected string
+	}{
+		{
+			name:  "left",
+			x:     1,
+			y:     0,
+			text:  "1",
+			style: TextStyle{Color: 0xFFFFFFFF, Scale: 1},
+			expected: strings.Join([]string{
+				"...#....",
+				"..##....",
+				"...#....",
+				"...#....",
+				"...#....",
+				"...#....",
+				"..###...",
+				"........",
+			}, "\n"),
+		},
+		{
+			name:  "right_clipped",
+			x:     4,
+			y:     -2,
+			text:  "-1",
+			style: TextStyle{Color: 0xFFFFFFFF, Scale: 1, Align: ALIGN_RIGHT},
+			expected: strings.Join([]string{
+				".#......",
+				".#......",
+				".#......",
+				".#......",
+				"###.....",
+				"........",
+				"........",
+				"........",
+			}, "\n"),
+		},
+		{
+			name:  "centered_scaled",
+			x:     4,
+			y:     -9,
+			text:  ".",
+			style: TextStyle{Color: 0xFFFFFFFF, Scale: 2, Align: ALIGN_CENTER},
+			expected: strings.Join([]string{
+				"........",
+				".####...",
+				".####...",
+				".####...",
+				".####...",
+				"........",
+				"........",
+				"........",
+			}, "\n"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			canvas := newTestCanvas(8, 8)
+			canvas.DrawText(tc.x, tc.y, tc.text, tc.style)
+
+			if got := canvas.render(tc.style.Color); got != tc.expected {
+				t.Errorf("Expected\n%s\ngot\n%s", tc.expected, got)
+			}
+		})
+	}
+}
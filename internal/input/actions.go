@@ -0,0 +1,67 @@
+package input
+
+// Action is something the player can do, independent of the key or button it's bound to.
+type Action int
+
+const (
+	ACTION_NONE Action = iota
+	ACTION_FORWARD
+	ACTION_BACKWARD
+	ACTION_TURN_LEFT
+	ACTION_TURN_RIGHT
+	ACTION_STRAFE_LEFT
+	ACTION_STRAFE_RIGHT
+	ACTION_RUN
+	ACTION_USE
+	ACTION_FIRE
+	ACTION_MAP
+
+	actionCount
+)
+
+var actionNames = [actionCount]string{
+	ACTION_NONE:         "none",
+	ACTION_FORWARD:      "forward",
+	ACTION_BACKWARD:     "backward",
+	ACTION_TURN_LEFT:    "turnLeft",
+	ACTION_TURN_RIGHT:   "turnRight",
+	ACTION_STRAFE_LEFT:  "strafeLeft",
+	ACTION_STRAFE_RIGHT: "strafeRight",
+	ACTION_RUN:          "run",
+	ACTION_USE:          "use",
+	ACTION_FIRE:         "fire",
+	ACTION_MAP:          "map",
+}
+
+func (a Action) String() string {
+	if a < 0 || a >= actionCount {
+		return "unknown"
+	}
+
+	return actionNames[a]
+}
+
+// Bindings maps physical keys to actions.
+type Bindings map[Key]Action
+
+// DefaultBindings returns the bindings used when none are configured.
+func DefaultBindings() Bindings {
+	return Bindings{
+		"w":     ACTION_FORWARD,
+		"up":    ACTION_FORWARD,
+		"s":     ACTION_BACKWARD,
+		"down":  ACTION_BACKWARD,
+		"a":     ACTION_TURN_LEFT,
+		"left":  ACTION_TURN_LEFT,
+		"d":     ACTION_TURN_RIGHT,
+		"right": ACTION_TURN_RIGHT,
+		"q":     ACTION_STRAFE_LEFT,
+		"e":     ACTION_STRAFE_RIGHT,
+
+		"leftShift":   ACTION_RUN,
+		"space":       ACTION_USE,
+		"leftControl": ACTION_FIRE,
+		"mouseLeft":   ACTION_FIRE,
+		"tab":         ACTION_MAP,
+	}
+}
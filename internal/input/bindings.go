@@ -0,0 +1,75 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ParseAction returns the action with the given name.
+func ParseAction(name string) (Action, bool) {
+	for action, actionName := range actionNames {
+		if actionName == name {
+			return Action(action), true
+		}
+	}
+
+	return ACTION_NONE, false
+}
+
+// LoadBindings loads a bindings file, a JSON object mapping physical key names to action names. Keys missing from the
+// file keep their default binding, binding a key to "none" unbinds it.
+func LoadBindings(filename string) (Bindings, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeBindings(content)
+}
+
+func decodeBindings(content []byte) (Bindings, error) {
+	rawBindings := map[string]string{}
+	if err := json.Unmarshal(content, &rawBindings); err != nil {
+		return nil, err
+	}
+
+	// Sorted for stable error messages.
+	keys := make([]string, 0, len(rawBindings))
+	for key := range rawBindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bindings := DefaultBindings()
+	errs := []error{}
+	for _, keyName := range keys {
+		actionName := rawBindings[keyName]
+
+		key := Key(keyName)
+		if !IsKnownKey(key) {
+			errs = append(errs, fmt.Errorf("unknown key %q", keyName))
+			continue
+		}
+
+		action, ok := ParseAction(actionName)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown action %q bound to key %q", actionName, keyName))
+			continue
+		}
+
+		if action == ACTION_NONE {
+			delete(bindings, key)
+		} else {
+			bindings[key] = action
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return bindings, nil
+}
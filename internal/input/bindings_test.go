@@ -0,0 +1,93 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_DecodeBindings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected Bindings
+		err      bool
+	}{
+		{
+			name:     "empty_uses_defaults",
+			data:     []byte(`{}`),
+			expected: DefaultBindings(),
+			err:      false,
+		},
+		{
+			name: "override_and_unbind",
+			data: []byte(`{
+				"a": "strafeLeft",
+				"d": "strafeRight",
+				"q": "none",
+				"space": "forward"
+			}`),
+			expected: Bindings{
+				"w":     ACTION_FORWARD,
+				"up":    ACTION_FORWARD,
+				"s":     ACTION_BACKWARD,
+				"down":  ACTION_BACKWARD,
+				"a":     ACTION_STRAFE_LEFT,
+				"left":  ACTION_TURN_LEFT,
+				"d":     ACTION_STRAFE_RIGHT,
+				"right": ACTION_TURN_RIGHT,
+				"e":     ACTION_STRAFE_RIGHT,
+				"space": ACTION_FORWARD,
+
+				"leftShift":   ACTION_RUN,
+				"leftControl": ACTION_FIRE,
+				"mouseLeft":   ACTION_FIRE,
+				"tab":         ACTION_MAP,
+			},
+			err: false,
+		},
+		{
+			name:     "unknown_key",
+			data:     []byte(`{"playerForward": "forward"}`),
+			expected: nil,
+			err:      true,
+		},
+		{
+			name:     "unknown_action",
+			data:     []byte(`{"w": "jump"}`),
+			expected: nil,
+			err:      true,
+		},
+		{
+			name:     "invalid_json",
+			data:     []byte(`This is not JSON`),
+			expected: nil,
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeBindings(tc.data)
+
+			if tc.err && err == nil {
+				t.Errorf("Expected err, got %v", err)
+			}
+
+			if !tc.err && err != nil {
+				t.Errorf("Did not expect error, got %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+// The shipped example must stay loadable.
+func Test_LoadBindingsDemo(t *testing.T) {
+	if _, err := LoadBindings("../../assets/bindings/strafe.json"); err != nil {
+		t.Errorf("Did not expect error, got %v", err)
+	}
+}
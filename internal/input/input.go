@@ -1,55 +1,193 @@
 package input
 
 import (
-	rp "github.com/rebay1982/redpix"
+	"encoding/json"
+	"io"
+	"sync"
 )
 
+// InputHandler receives input events from the rendering thread and is read from the update loop, it is safe for
+// concurrent use. Physical key events are translated into actions through a binding table, game code only sees actions.
 type InputHandler struct {
-	input InputVector
+	mu       sync.Mutex
+	bindings Bindings
+	input    InputVector
+	// Keys held down, and how many of them each action is bound to. An action is held until all its keys are released.
+	keysDown   map[Key]bool
+	actionKeys [actionCount]int
+
+	// Polls since the recording or replay started, the game polls once per tick.
+	polls    uint64
+	recorder *inputRecorder
+	// Live input is ignored while replaying.
+	replay *inputReplay
 }
 
+// InputVector is the state of every action, along with the mouse movement accumulated since the previous poll.
 type InputVector struct {
-	PlayerForward  bool
-	PlayerBackward bool
-	PlayerLeft     bool
-	PlayerRight    bool
+	actions     [actionCount]bool
+	MouseDeltaX float64
 }
 
-// NewInputHandler creates a new InputHandler.
+// IsActive returns true if the action is currently held.
+func (iv InputVector) IsActive(action Action) bool {
+	if action <= ACTION_NONE || action >= actionCount {
+		return false
+	}
+
+	return iv.actions[action]
+}
+
+// NewInputHandler creates a new InputHandler using the default bindings.
 func NewInputHandler() *InputHandler {
 	i := &InputHandler{
-		input: InputVector{},
+		bindings: DefaultBindings(),
+		input:    InputVector{},
+		keysDown: map[Key]bool{},
 	}
 
 	return i
 }
 
-// HandleInputEvent handles an input event from the redpix library.
-func (i *InputHandler) HandleInputEvent(e rp.InputEvent) {
-	// Ignore repeated key presses.
-	if e.Action == rp.IN_ACT_REPEATED {
+// SetBindings replaces the binding table. Actions held through the previous bindings are released.
+func (i *InputHandler) SetBindings(bindings Bindings) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.bindings = bindings
+	i.input.actions = [actionCount]bool{}
+	i.keysDown = map[Key]bool{}
+	i.actionKeys = [actionCount]int{}
+}
+
+// HandleKeyEvent handles a physical key being pressed or released. Repeated presses of a key held down are ignored.
+func (i *InputHandler) HandleKeyEvent(key Key, pressed bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	action, ok := i.bindings[key]
+	if !ok || i.keysDown[key] == pressed {
+		return
+	}
+
+	i.keysDown[key] = pressed
+	if pressed {
+		i.actionKeys[action]++
+	} else {
+		i.actionKeys[action]--
+	}
+
+	if i.replay == nil {
+		i.input.actions[action] = i.actionKeys[action] > 0
+	}
+}
+
+// SetAction sets whether an action is held, for input sources that don't go through the binding table.
+func (i *InputHandler) SetAction(action Action, active bool) {
+	if action <= ACTION_NONE || action >= actionCount {
 		return
 	}
 
-	setInput := false
-	// Released handling is implicit.
-	if e.Action == rp.IN_ACT_PRESSED {
-		setInput = true
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.replay != nil {
+		return
 	}
 
-	switch e.Key {
-	case rp.IN_PLAYER_FORWARD:
-		i.input.PlayerForward = setInput
-	case rp.IN_PLAYER_BACKWARD:
-		i.input.PlayerBackward = setInput
-	case rp.IN_PLAYER_LEFT:
-		i.input.PlayerLeft = setInput
-	case rp.IN_PLAYER_RIGHT:
-		i.input.PlayerRight = setInput
+	i.input.actions[action] = active
+}
+
+// HandleMouseMove accumulates horizontal mouse movement, in pixels, until the next poll. Positive values look right.
+func (i *InputHandler) HandleMouseMove(deltaX float64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.replay != nil {
+		return
 	}
+
+	i.input.MouseDeltaX += deltaX
 }
 
-// GetInputVector returns the latest input vector.
-func (i InputHandler) GetInputVector() InputVector {
-	return i.input
+// PollInputVector returns the latest input vector and resets the accumulated mouse movement. While replaying, the input
+// vector is the recorded one.
+func (i *InputHandler) PollInputVector() InputVector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Held actions are released once the replay is over, live input takes over from there.
+	if i.replay != nil && !i.replay.apply(i.polls, &i.input) {
+		i.replay = nil
+		i.input = InputVector{}
+	}
+
+	input := i.input
+	i.input.MouseDeltaX = 0.0
+
+	if i.recorder != nil {
+		i.recorder.record(i.polls, input)
+	}
+	i.polls++
+
+	return input
+}
+
+// StartRecording writes the input vectors polled from now on, starting with the header. The recording must be stopped
+// for the replay to know where it ends.
+func (i *InputHandler) StartRecording(w io.Writer, header RecordingHeader) error {
+	header.Version = RECORDING_VERSION
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.polls = 0
+	i.recorder = &inputRecorder{encoder: encoder}
+
+	return nil
+}
+
+// StopRecording marks the end of the recording at the last tick polled. Returns the first error writing the recording.
+func (i *InputHandler) StopRecording() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.recorder == nil {
+		return nil
+	}
+
+	end := uint64(0)
+	if i.polls > 0 {
+		end = i.polls - 1
+	}
+	i.recorder.write(RecordedEvent{Tick: end, End: true})
+
+	err := i.recorder.err
+	i.recorder = nil
+
+	return err
+}
+
+// StartReplay replaces live input with a recording, from the next poll on. The first tick polled gets the recording's
+// first tick of input.
+func (i *InputHandler) StartReplay(recording Recording) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.polls = 0
+	i.input = InputVector{}
+	i.replay = newInputReplay(recording)
+}
+
+// IsReplaying returns true until the replay is over, which is noticed by the first poll after its last tick.
+func (i *InputHandler) IsReplaying() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.replay != nil
 }
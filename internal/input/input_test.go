@@ -0,0 +1,112 @@
+package input
+
+import (
+	"testing"
+)
+
+// keyEvent is a physical key being pressed or released.
+type keyEvent struct {
+	key     Key
+	pressed bool
+}
+
+func Test_InputHandlerHandleKeyEvent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		bindings   Bindings
+		events     []keyEvent
+		wantActive []Action
+	}{
+		{
+			name:       "default_bindings",
+			events:     []keyEvent{{key: "w", pressed: true}, {key: "e", pressed: true}},
+			wantActive: []Action{ACTION_FORWARD, ACTION_STRAFE_RIGHT},
+		},
+		{
+			name: "released",
+			events: []keyEvent{
+				{key: "a", pressed: true},
+				{key: "d", pressed: true},
+				{key: "a", pressed: false},
+			},
+			wantActive: []Action{ACTION_TURN_RIGHT},
+		},
+		{
+			name:       "repeated_ignored",
+			events:     []keyEvent{{key: "s", pressed: true}, {key: "s", pressed: true}, {key: "s", pressed: false}},
+			wantActive: []Action{},
+		},
+		{
+			name: "run_use_and_fire",
+			events: []keyEvent{
+				{key: "leftShift", pressed: true},
+				{key: "space", pressed: true},
+				{key: "mouseLeft", pressed: true},
+			},
+			wantActive: []Action{ACTION_RUN, ACTION_USE, ACTION_FIRE},
+		},
+		{
+			name: "held_by_another_key",
+			events: []keyEvent{
+				{key: "w", pressed: true},
+				{key: "up", pressed: true},
+				{key: "w", pressed: false},
+			},
+			wantActive: []Action{ACTION_FORWARD},
+		},
+		{
+			name:       "unbound_key",
+			events:     []keyEvent{{key: "z", pressed: true}},
+			wantActive: []Action{},
+		},
+		{
+			name: "custom_bindings",
+			bindings: Bindings{
+				"a": ACTION_STRAFE_LEFT,
+				"d": ACTION_STRAFE_RIGHT,
+			},
+			events:     []keyEvent{{key: "a", pressed: true}, {key: "w", pressed: true}},
+			wantActive: []Action{ACTION_STRAFE_LEFT},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inputHandler := NewInputHandler()
+			if tc.bindings != nil {
+				inputHandler.SetBindings(tc.bindings)
+			}
+
+			for _, event := range tc.events {
+				inputHandler.HandleKeyEvent(event.key, event.pressed)
+			}
+
+			inputVector := inputHandler.PollInputVector()
+			for action := ACTION_NONE; action < actionCount; action++ {
+				want := false
+				for _, active := range tc.wantActive {
+					want = want || active == action
+				}
+
+				if got := inputVector.IsActive(action); got != want {
+					t.Errorf("Expected action %s active %t, got %t", action, want, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_InputHandlerPollMouseDelta(t *testing.T) {
+	inputHandler := NewInputHandler()
+	inputHandler.HandleMouseMove(3.0)
+	inputHandler.HandleMouseMove(-1.0)
+
+	if got := inputHandler.PollInputVector().MouseDeltaX; got != 2.0 {
+		t.Errorf("Expected accumulated mouse delta 2.0, got %f", got)
+	}
+
+	// The delta is consumed by polling.
+	if got := inputHandler.PollInputVector().MouseDeltaX; got != 0.0 {
+		t.Errorf("Expected mouse delta 0.0 after polling, got %f", got)
+	}
+}
@@ -0,0 +1,30 @@
+package input
+
+// Key is a physical key or a mouse button. Keys are named after the key at the same position on a US layout, bindings
+// follow the position of keys rather than their label. The default W, A, S and D bindings are on Z, Q, S and D on an
+// AZERTY keyboard.
+type Key string
+
+// Keys other than letters and digits, which are named after themselves ("a" to "z", "0" to "9"). Escape can't be bound,
+// it releases the mouse.
+var namedKeys = []Key{
+	"space", "tab", "enter", "backspace",
+	"up", "down", "left", "right",
+	"leftShift", "rightShift", "leftControl", "rightControl", "leftAlt", "rightAlt",
+	"mouseLeft", "mouseRight", "mouseMiddle",
+}
+
+// IsKnownKey returns true if the key can be bound.
+func IsKnownKey(key Key) bool {
+	if len(key) == 1 && (key[0] >= 'a' && key[0] <= 'z' || key[0] >= '0' && key[0] <= '9') {
+		return true
+	}
+
+	for _, namedKey := range namedKeys {
+		if namedKey == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,171 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Format version of recording files, bumped when recordings made by older versions can't be replayed anymore.
+	RECORDING_VERSION = 1
+)
+
+// RecordingHeader describes the game a recording was made in. A replay only follows the recorded path when it's
+// started from the same level, position and game settings.
+type RecordingHeader struct {
+	Version int    `json:"version"`
+	Level   string `json:"level"`
+
+	TickRate         int     `json:"tickRate"`
+	MoveSpeed        float64 `json:"moveSpeed"`
+	TurnSpeed        float64 `json:"turnSpeed"`
+	MouseSensitivity float64 `json:"mouseSensitivity"`
+	PlayerRadius     float64 `json:"playerRadius"`
+
+	PlayerX     float64 `json:"playerX"`
+	PlayerY     float64 `json:"playerY"`
+	PlayerAngle float64 `json:"playerAngle"`
+}
+
+// RecordedEvent is a change of the input vector, timestamped with the tick of the poll that first returned it. The
+// game polls once per tick, the first tick being 0. The last event of a complete recording marks its end.
+type RecordedEvent struct {
+	Tick        uint64  `json:"tick"`
+	Action      string  `json:"action,omitempty"`
+	Active      bool    `json:"active,omitempty"`
+	MouseDeltaX float64 `json:"mouseDeltaX,omitempty"`
+	End         bool    `json:"end,omitempty"`
+}
+
+// Recording is a recorded action stream. Recording files hold a JSON object per line, the header followed by the
+// events in tick order.
+type Recording struct {
+	Header RecordingHeader
+	Events []RecordedEvent
+}
+
+// inputRecorder writes the changes of the polled input vectors.
+type inputRecorder struct {
+	encoder  *json.Encoder
+	previous InputVector
+	// First error writing the recording, nothing is written after it.
+	err error
+}
+
+func (r *inputRecorder) write(event RecordedEvent) {
+	if r.err == nil {
+		r.err = r.encoder.Encode(event)
+	}
+}
+
+// record writes the actions that changed since the previous poll and the mouse movement of this poll.
+func (r *inputRecorder) record(tick uint64, input InputVector) {
+	for action := ACTION_NONE + 1; action < actionCount; action++ {
+		if input.actions[action] != r.previous.actions[action] {
+			r.write(RecordedEvent{Tick: tick, Action: action.String(), Active: input.actions[action]})
+		}
+	}
+
+	if input.MouseDeltaX != 0.0 {
+		r.write(RecordedEvent{Tick: tick, MouseDeltaX: input.MouseDeltaX})
+	}
+
+	r.previous = input
+}
+
+// inputReplay feeds recorded events back into the input vector, a poll at a time.
+type inputReplay struct {
+	events []RecordedEvent
+	next   int
+	// Tick after which the replay is over.
+	end uint64
+}
+
+func newInputReplay(recording Recording) *inputReplay {
+	replay := &inputReplay{
+		events: recording.Events,
+	}
+
+	// Recordings cut short, by a crash for example, end with their last event.
+	if len(recording.Events) > 0 {
+		replay.end = recording.Events[len(recording.Events)-1].Tick
+	}
+
+	return replay
+}
+
+// apply applies the events of a tick to the input vector. Returns false once the replay is over.
+func (r *inputReplay) apply(tick uint64, input *InputVector) bool {
+	if tick > r.end {
+		return false
+	}
+
+	input.MouseDeltaX = 0.0
+	for ; r.next < len(r.events) && r.events[r.next].Tick <= tick; r.next++ {
+		event := r.events[r.next]
+		if event.MouseDeltaX != 0.0 {
+			input.MouseDeltaX += event.MouseDeltaX
+		}
+
+		if action, ok := ParseAction(event.Action); ok && action != ACTION_NONE {
+			input.actions[action] = event.Active
+		}
+	}
+
+	return true
+}
+
+// LoadRecording loads a recording file.
+func LoadRecording(filename string) (Recording, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return Recording{}, err
+	}
+
+	return DecodeRecording(bytes.NewReader(content))
+}
+
+// DecodeRecording decodes a recording, checking that it can be replayed.
+func DecodeRecording(reader io.Reader) (Recording, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	recording := Recording{}
+	if err := decoder.Decode(&recording.Header); err != nil {
+		return Recording{}, fmt.Errorf("Failed to decode recording header: %w", err)
+	}
+
+	if recording.Header.Version != RECORDING_VERSION {
+		return Recording{}, fmt.Errorf("Unsupported recording version %d, expected %d", recording.Header.Version,
+			RECORDING_VERSION)
+	}
+
+	for {
+		event := RecordedEvent{}
+		err := decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Recording{}, fmt.Errorf("Failed to decode recorded event %d: %w", len(recording.Events), err)
+		}
+
+		if event.Action != "" {
+			if _, ok := ParseAction(event.Action); !ok {
+				return Recording{}, fmt.Errorf("Unknown action %q recorded at tick %d", event.Action, event.Tick)
+			}
+		}
+
+		if n := len(recording.Events); n > 0 && event.Tick < recording.Events[n-1].Tick {
+			return Recording{}, fmt.Errorf("Recorded event at tick %d is out of order", event.Tick)
+		}
+
+		recording.Events = append(recording.Events, event)
+	}
+
+	return recording, nil
+}
@@ -0,0 +1,166 @@
+package input
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_InputRecording(t *testing.T) {
+	i := NewInputHandler()
+	i.SetAction(ACTION_FORWARD, true)
+
+	out := &bytes.Buffer{}
+	if err := i.StartRecording(out, RecordingHeader{Level: "level.json", TickRate: 120}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// Tick 0 records the action held before the recording started.
+	i.PollInputVector()
+	i.HandleMouseMove(2.5)
+	i.SetAction(ACTION_TURN_LEFT, true)
+	i.PollInputVector()
+	i.PollInputVector()
+	i.SetAction(ACTION_FORWARD, false)
+	i.PollInputVector()
+
+	if err := i.StopRecording(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	// Polls after the recording stopped aren't recorded.
+	i.SetAction(ACTION_USE, true)
+	i.PollInputVector()
+
+	expected := []string{
+		`{"version":1,"level":"level.json","tickRate":120,"moveSpeed":0,"turnSpeed":0,"mouseSensitivity":0,` +
+			`"playerRadius":0,"playerX":0,"playerY":0,"playerAngle":0}`,
+		`{"tick":0,"action":"forward","active":true}`,
+		`{"tick":1,"action":"turnLeft","active":true}`,
+		`{"tick":1,"mouseDeltaX":2.5}`,
+		`{"tick":3,"action":"forward"}`,
+		`{"tick":3,"end":true}`,
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if diff := cmp.Diff(expected, lines); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
+
+func Test_InputReplay(t *testing.T) {
+	recording, err := DecodeRecording(strings.NewReader(`{"version":1,"tickRate":120}
+{"tick":0,"action":"forward","active":true}
+{"tick":1,"action":"turnLeft","active":true}
+{"tick":1,"mouseDeltaX":2.5}
+{"tick":3,"action":"forward"}
+{"tick":3,"end":true}
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	i := NewInputHandler()
+	i.StartReplay(recording)
+
+	type polled struct {
+		Forward  bool
+		TurnLeft bool
+		Use      bool
+		Mouse    float64
+	}
+
+	got := []polled{}
+	for tick := 0; tick < 6; tick++ {
+		// Live input is ignored during the replay, and used again once it's over.
+		i.SetAction(ACTION_USE, true)
+		i.HandleMouseMove(1.0)
+
+		input := i.PollInputVector()
+		got = append(got, polled{
+			Forward:  input.IsActive(ACTION_FORWARD),
+			TurnLeft: input.IsActive(ACTION_TURN_LEFT),
+			Use:      input.IsActive(ACTION_USE),
+			Mouse:    input.MouseDeltaX,
+		})
+	}
+
+	expected := []polled{
+		{Forward: true},
+		{Forward: true, TurnLeft: true, Mouse: 2.5},
+		{Forward: true, TurnLeft: true},
+		{TurnLeft: true},
+		{},
+		{Use: true, Mouse: 1.0},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+
+	if i.IsReplaying() {
+		t.Errorf("Expected the replay to be over")
+	}
+}
+
+func Test_DecodeRecording(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		expected  Recording
+		expectErr bool
+	}{
+		{
+			name:    "header_only",
+			content: `{"version":1,"level":"level.json","tickRate":60}`,
+			expected: Recording{
+				Header: RecordingHeader{Version: 1, Level: "level.json", TickRate: 60},
+			},
+		},
+		{
+			name:      "empty",
+			content:   ``,
+			expectErr: true,
+		},
+		{
+			name:      "unsupported_version",
+			content:   `{"version":2}`,
+			expectErr: true,
+		},
+		{
+			name:      "unknown_field",
+			content:   `{"version":1}` + "\n" + `{"tick":0,"key":"w"}`,
+			expectErr: true,
+		},
+		{
+			name:      "unknown_action",
+			content:   `{"version":1}` + "\n" + `{"tick":0,"action":"jump","active":true}`,
+			expectErr: true,
+		},
+		{
+			name:      "out_of_order",
+			content:   `{"version":1}` + "\n" + `{"tick":5,"mouseDeltaX":1}` + "\n" + `{"tick":4,"mouseDeltaX":1}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeRecording(strings.NewReader(tc.content))
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
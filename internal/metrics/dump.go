@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type dumpFormat int
+
+const (
+	dumpFormatCsv dumpFormat = iota
+	dumpFormatJson
+)
+
+// statsRecord is a line of a dump, times are in microseconds.
+type statsRecord struct {
+	Time     time.Time          `json:"time"`
+	Frames   int                `json:"frames"`
+	Fps      float64            `json:"fps"`
+	MinUs    float64            `json:"minUs"`
+	AvgUs    float64            `json:"avgUs"`
+	P95Us    float64            `json:"p95Us"`
+	P99Us    float64            `json:"p99Us"`
+	MaxUs    float64            `json:"maxUs"`
+	StagesUs map[string]float64 `json:"stagesUs"`
+}
+
+func newStatsRecord(now time.Time, stats Stats) statsRecord {
+	record := statsRecord{
+		Time:     now,
+		Frames:   stats.Frames,
+		Fps:      stats.Fps,
+		MinUs:    toMicroseconds(stats.Min),
+		AvgUs:    toMicroseconds(stats.Avg),
+		P95Us:    toMicroseconds(stats.P95),
+		P99Us:    toMicroseconds(stats.P99),
+		MaxUs:    toMicroseconds(stats.Max),
+		StagesUs: make(map[string]float64, StageCount),
+	}
+
+	for stage := STAGE_FLOOR; stage < StageCount; stage++ {
+		record.StagesUs[stage.String()] = toMicroseconds(stats.Stages[stage])
+	}
+
+	return record
+}
+
+func toMicroseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+func csvHeader() []string {
+	header := []string{"time", "frames", "fps", "minUs", "avgUs", "p95Us", "p99Us", "maxUs"}
+	for stage := STAGE_FLOOR; stage < StageCount; stage++ {
+		header = append(header, stage.String()+"Us")
+	}
+
+	return header
+}
+
+func (s statsRecord) csvRow() []string {
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 3, 64)
+	}
+
+	row := []string{
+		s.Time.Format(time.RFC3339Nano),
+		strconv.Itoa(s.Frames),
+		format(s.Fps),
+		format(s.MinUs),
+		format(s.AvgUs),
+		format(s.P95Us),
+		format(s.P99Us),
+		format(s.MaxUs),
+	}
+	for stage := STAGE_FLOOR; stage < StageCount; stage++ {
+		row = append(row, format(s.StagesUs[stage.String()]))
+	}
+
+	return row
+}
+
+// dump writes the statistics of the frames recorded during each interval, a line per interval.
+type dump struct {
+	out      io.Writer
+	closer   io.Closer
+	format   dumpFormat
+	interval time.Duration
+
+	lastWrite time.Time
+	frames    []Frame
+}
+
+// createDump creates a dump file. The format is selected by the file's extension, .csv or .json. JSON dumps hold an
+// object per line.
+func createDump(filename string, interval time.Duration) (*dump, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Invalid metrics dump interval %v, expected a positive duration", interval)
+	}
+
+	var format dumpFormat
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		format = dumpFormatCsv
+	case ".json":
+		format = dumpFormatJson
+	default:
+		return nil, fmt.Errorf("Unknown metrics file format %s, expected .csv or .json", filename)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create metrics file %s: %w", filename, err)
+	}
+
+	d, err := newDump(file, format, interval, time.Now())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	d.closer = file
+
+	return d, nil
+}
+
+func newDump(out io.Writer, format dumpFormat, interval time.Duration, now time.Time) (*dump, error) {
+	d := &dump{
+		out:       out,
+		format:    format,
+		interval:  interval,
+		lastWrite: now,
+	}
+
+	if format == dumpFormatCsv {
+		if err := d.writeCsv(csvHeader()); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// add adds a frame to the current interval, and writes the interval once it's over.
+func (d *dump) add(now time.Time, frame Frame) error {
+	d.frames = append(d.frames, frame)
+
+	if now.Sub(d.lastWrite) < d.interval {
+		return nil
+	}
+
+	return d.flush(now)
+}
+
+// flush writes the frames recorded since the last write, if any.
+func (d *dump) flush(now time.Time) error {
+	d.lastWrite = now
+	if len(d.frames) == 0 {
+		return nil
+	}
+
+	record := newStatsRecord(now, ComputeStats(d.frames))
+	d.frames = d.frames[:0]
+
+	if d.format == dumpFormatJson {
+		return json.NewEncoder(d.out).Encode(record)
+	}
+
+	return d.writeCsv(record.csvRow())
+}
+
+func (d *dump) writeCsv(row []string) error {
+	w := csv.NewWriter(d.out)
+	w.Write(row)
+	w.Flush()
+
+	return w.Error()
+}
+
+func (d *dump) close(now time.Time) error {
+	err := d.flush(now)
+
+	if d.closer != nil {
+		if closeErr := d.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
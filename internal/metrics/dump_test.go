@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Dump(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	frame := Frame{
+		FrameTime: 10 * time.Millisecond,
+		Stages: [StageCount]time.Duration{
+			STAGE_FLOOR:   time.Millisecond,
+			STAGE_WALLS:   2500 * time.Microsecond,
+			STAGE_PRESENT: 500 * time.Microsecond,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		format   dumpFormat
+		expected []string
+	}{
+		{
+			name:   "csv",
+			format: dumpFormatCsv,
+			expected: []string{
+				"time,frames,fps,minUs,avgUs,p95Us,p99Us,maxUs,floorUs,ceilingUs,wallsUs,spritesUs,overlayUs,presentUs",
+				"2024-01-02T03:04:06Z,2,100.000,10000.000,10000.000,10000.000,10000.000,10000.000," +
+					"1000.000,0.000,2500.000,0.000,0.000,500.000",
+				"2024-01-02T03:04:07Z,1,100.000,10000.000,10000.000,10000.000,10000.000,10000.000," +
+					"1000.000,0.000,2500.000,0.000,0.000,500.000",
+			},
+		},
+		{
+			name:   "json",
+			format: dumpFormatJson,
+			expected: []string{
+				`{"time":"2024-01-02T03:04:06Z","frames":2,"fps":100,"minUs":10000,"avgUs":10000,"p95Us":10000,` +
+					`"p99Us":10000,"maxUs":10000,"stagesUs":{"ceiling":0,"floor":1000,"overlay":0,"present":500,` +
+					`"sprites":0,"walls":2500}}`,
+				`{"time":"2024-01-02T03:04:07Z","frames":1,"fps":100,"minUs":10000,"avgUs":10000,"p95Us":10000,` +
+					`"p99Us":10000,"maxUs":10000,"stagesUs":{"ceiling":0,"floor":1000,"overlay":0,"present":500,` +
+					`"sprites":0,"walls":2500}}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			d, err := newDump(out, tc.format, time.Second, start)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			// Two frames in the first interval, the last one is written when the dump is closed.
+			for _, offset := range []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond} {
+				if err := d.add(start.Add(offset), frame); err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+			}
+			if err := d.close(start.Add(2 * time.Second)); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if diff := cmp.Diff(tc.expected, lines); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_RecorderStartDump(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name          string
+		filename      string
+		interval      time.Duration
+		expectedLines int
+		expectErr     bool
+	}{
+		{
+			name:          "csv_file",
+			filename:      "metrics.csv",
+			interval:      time.Second,
+			expectedLines: 2,
+		},
+		{
+			name:          "json_file",
+			filename:      "metrics.JSON",
+			interval:      time.Second,
+			expectedLines: 1,
+		},
+		{
+			name:      "unknown_extension",
+			filename:  "metrics.txt",
+			interval:  time.Second,
+			expectErr: true,
+		},
+		{
+			name:      "invalid_interval",
+			filename:  "metrics.csv",
+			interval:  0,
+			expectErr: true,
+		},
+		{
+			name:      "missing_directory",
+			filename:  filepath.Join("missing", "metrics.csv"),
+			interval:  time.Second,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tc.name+"-"+filepath.Base(tc.filename))
+			if filepath.Dir(tc.filename) != "." {
+				filename = filepath.Join(dir, tc.filename)
+			}
+
+			r := NewRecorder()
+			err := r.StartDump(filename, tc.interval)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			r.BeginFrame()
+			r.EndFrame()
+			if err := r.Close(); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if lines := strings.Count(string(content), "\n"); lines != tc.expectedLines {
+				t.Errorf("Expected %d lines, got %d in %q", tc.expectedLines, lines, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	// Frames the on-screen statistics are computed over.
+	WINDOW_SIZE = 100
+)
+
+// Stage is a part of a frame whose time is measured separately.
+type Stage int
+
+const (
+	STAGE_FLOOR Stage = iota
+	STAGE_CEILING
+	STAGE_WALLS
+	STAGE_SPRITES
+	// Map and HUD, drawn over the rendered world.
+	STAGE_OVERLAY
+	// Time between the end of the previous frame and the start of this one, spent handing the previous frame to the
+	//	window and waiting for the next draw.
+	STAGE_PRESENT
+
+	StageCount
+)
+
+var stageNames = [StageCount]string{
+	STAGE_FLOOR:   "floor",
+	STAGE_CEILING: "ceiling",
+	STAGE_WALLS:   "walls",
+	STAGE_SPRITES: "sprites",
+	STAGE_OVERLAY: "overlay",
+	STAGE_PRESENT: "present",
+}
+
+func (s Stage) String() string {
+	if s < 0 || s >= StageCount {
+		return "unknown"
+	}
+
+	return stageNames[s]
+}
+
+// Frame holds the timings of a frame. The frame time goes from the end of the previous frame to the end of this one,
+// present included. Stages drawn by the rendering threads add up the time of every thread, with more than one thread
+// they can exceed the frame time.
+type Frame struct {
+	FrameTime time.Duration
+	Stages    [StageCount]time.Duration
+}
+
+// Stats summarizes the timings of a series of frames. The stage times are averages per frame.
+type Stats struct {
+	Frames int
+	Fps    float64
+	Min    time.Duration
+	Avg    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+	Stages [StageCount]time.Duration
+}
+
+// ComputeStats summarizes frames, in any order.
+func ComputeStats(frames []Frame) Stats {
+	if len(frames) == 0 {
+		return Stats{}
+	}
+
+	frameTimes := make([]time.Duration, len(frames))
+	stats := Stats{
+		Frames: len(frames),
+	}
+
+	var total time.Duration
+	for i, frame := range frames {
+		frameTimes[i] = frame.FrameTime
+		total += frame.FrameTime
+
+		for stage, stageTime := range frame.Stages {
+			stats.Stages[stage] += stageTime
+		}
+	}
+
+	for stage := range stats.Stages {
+		stats.Stages[stage] /= time.Duration(len(frames))
+	}
+
+	slices.Sort(frameTimes)
+	stats.Min = frameTimes[0]
+	stats.Max = frameTimes[len(frameTimes)-1]
+	stats.Avg = total / time.Duration(len(frames))
+	stats.P95 = percentile(frameTimes, 0.95)
+	stats.P99 = percentile(frameTimes, 0.99)
+	if stats.Avg > 0 {
+		stats.Fps = float64(time.Second) / float64(stats.Avg)
+	}
+
+	return stats
+}
+
+// percentile returns the nearest rank percentile of sorted values, p being between 0 and 1.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
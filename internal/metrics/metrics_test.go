@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ComputeStats(t *testing.T) {
+	// Frames of 1 to 100 milliseconds, half of which is spent drawing walls.
+	ramp := make([]Frame, 100)
+	for i := range ramp {
+		ramp[i].FrameTime = time.Duration(i+1) * time.Millisecond
+		ramp[i].Stages[STAGE_WALLS] = ramp[i].FrameTime / 2
+	}
+
+	testCases := []struct {
+		name     string
+		frames   []Frame
+		expected Stats
+	}{
+		{
+			name:     "no_frames",
+			frames:   nil,
+			expected: Stats{},
+		},
+		{
+			name: "single_frame",
+			frames: []Frame{
+				{FrameTime: 10 * time.Millisecond, Stages: [StageCount]time.Duration{STAGE_FLOOR: 2 * time.Millisecond}},
+			},
+			expected: Stats{
+				Frames: 1,
+				Fps:    100.0,
+				Min:    10 * time.Millisecond,
+				Avg:    10 * time.Millisecond,
+				P95:    10 * time.Millisecond,
+				P99:    10 * time.Millisecond,
+				Max:    10 * time.Millisecond,
+				Stages: [StageCount]time.Duration{STAGE_FLOOR: 2 * time.Millisecond},
+			},
+		},
+		{
+			name: "unordered_frames",
+			frames: []Frame{
+				{FrameTime: 30 * time.Millisecond},
+				{FrameTime: 10 * time.Millisecond},
+				{FrameTime: 20 * time.Millisecond},
+			},
+			expected: Stats{
+				Frames: 3,
+				Fps:    50.0,
+				Min:    10 * time.Millisecond,
+				Avg:    20 * time.Millisecond,
+				P95:    30 * time.Millisecond,
+				P99:    30 * time.Millisecond,
+				Max:    30 * time.Millisecond,
+			},
+		},
+		{
+			name:   "percentiles",
+			frames: ramp,
+			expected: Stats{
+				Frames: 100,
+				Fps:    float64(time.Second) / float64(50500*time.Microsecond),
+				Min:    time.Millisecond,
+				Avg:    50500 * time.Microsecond,
+				P95:    95 * time.Millisecond,
+				P99:    99 * time.Millisecond,
+				Max:    100 * time.Millisecond,
+				Stages: [StageCount]time.Duration{STAGE_WALLS: 25250 * time.Microsecond},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, ComputeStats(tc.frames)); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func Test_StageString(t *testing.T) {
+	names := []string{}
+	for stage := STAGE_FLOOR; stage <= StageCount; stage++ {
+		names = append(names, stage.String())
+	}
+
+	expected := []string{"floor", "ceiling", "walls", "sprites", "overlay", "present", "unknown"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"time"
+)
+
+// Recorder records the timings of the frames drawn. Frames are begun and ended by the goroutine drawing them, the
+// rendering threads time their stages with their own StageTimer so they never share a counter.
+type Recorder struct {
+	// Stage times of the frame being drawn, by rendering thread.
+	threadStages [][StageCount]time.Duration
+
+	frame        Frame
+	frameStart   time.Time
+	lastFrameEnd time.Time
+
+	// Last frames recorded, for the on-screen statistics.
+	window      [WINDOW_SIZE]Frame
+	windowIndex int
+	windowCount int
+	windowSum   time.Duration
+
+	dump *dump
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		threadStages: make([][StageCount]time.Duration, 1),
+	}
+}
+
+// SetThreads sets the number of rendering threads timing stages. Must not be called while a frame is being drawn.
+func (r *Recorder) SetThreads(threads int) {
+	r.threadStages = make([][StageCount]time.Duration, max(threads, 1))
+}
+
+// ThreadTimer returns a timer adding to the stages of a rendering thread, starting now. A nil recorder returns a timer
+// that doesn't measure anything, so rendering code doesn't have to check if metrics are enabled.
+func (r *Recorder) ThreadTimer(thread int) StageTimer {
+	if r == nil {
+		return StageTimer{}
+	}
+
+	return StageTimer{
+		stages: &r.threadStages[thread],
+		mark:   time.Now(),
+	}
+}
+
+// BeginFrame starts timing a frame. Stages timed since the last frame ended are discarded.
+func (r *Recorder) BeginFrame() {
+	r.frameStart = time.Now()
+	r.frame = Frame{}
+	for thread := range r.threadStages {
+		r.threadStages[thread] = [StageCount]time.Duration{}
+	}
+}
+
+// Time adds the time since start to a stage of the frame being drawn. Only for stages timed by the goroutine drawing
+// the frame, rendering threads use a StageTimer.
+func (r *Recorder) Time(stage Stage, start time.Time) {
+	r.frame.Stages[stage] += time.Since(start)
+}
+
+// EndFrame stops timing the frame and records it. Returns an error if the periodic dump failed to be written.
+func (r *Recorder) EndFrame() error {
+	now := time.Now()
+	return r.endFrame(now)
+}
+
+func (r *Recorder) endFrame(now time.Time) error {
+	for thread := range r.threadStages {
+		for stage, stageTime := range r.threadStages[thread] {
+			r.frame.Stages[stage] += stageTime
+		}
+		r.threadStages[thread] = [StageCount]time.Duration{}
+	}
+
+	// The first frame has nothing to present before it.
+	if !r.lastFrameEnd.IsZero() {
+		r.frame.Stages[STAGE_PRESENT] += r.frameStart.Sub(r.lastFrameEnd)
+	}
+	r.frame.FrameTime = now.Sub(r.frameStart) + r.frame.Stages[STAGE_PRESENT]
+	r.lastFrameEnd = now
+
+	r.record(r.frame)
+
+	if r.dump != nil {
+		return r.dump.add(now, r.frame)
+	}
+
+	return nil
+}
+
+func (r *Recorder) record(frame Frame) {
+	r.windowSum += frame.FrameTime - r.window[r.windowIndex].FrameTime
+	r.window[r.windowIndex] = frame
+	r.windowIndex = (r.windowIndex + 1) % len(r.window)
+	r.windowCount = min(r.windowCount+1, len(r.window))
+}
+
+// GetFps returns the average frame rate of the last frames recorded, 0 before the first frame.
+func (r *Recorder) GetFps() float64 {
+	if r.windowSum <= 0 {
+		return 0.0
+	}
+
+	return float64(r.windowCount) * float64(time.Second) / float64(r.windowSum)
+}
+
+// LastFrame returns the last frame recorded.
+func (r *Recorder) LastFrame() Frame {
+	return r.window[(r.windowIndex+len(r.window)-1)%len(r.window)]
+}
+
+// Stats summarizes the last frames recorded.
+func (r *Recorder) Stats() Stats {
+	return ComputeStats(r.window[:r.windowCount])
+}
+
+// StartDump writes the statistics of the frames recorded to a file at every interval, as CSV or JSON lines depending on
+// the file's extension. The file is overwritten.
+func (r *Recorder) StartDump(filename string, interval time.Duration) error {
+	d, err := createDump(filename, interval)
+	if err != nil {
+		return err
+	}
+
+	r.dump = d
+	return nil
+}
+
+// Close writes the frames recorded since the last dump and closes the dump file, if any.
+func (r *Recorder) Close() error {
+	if r.dump == nil {
+		return nil
+	}
+
+	err := r.dump.close(time.Now())
+	r.dump = nil
+
+	return err
+}
+
+// StageTimer times the stages drawn by a rendering thread, each lap adding the time since the previous one to a stage.
+type StageTimer struct {
+	stages *[StageCount]time.Duration
+	mark   time.Time
+}
+
+// Lap adds the time since the last lap, or since the timer was created, to a stage.
+func (t *StageTimer) Lap(stage Stage) {
+	if t.stages == nil {
+		return
+	}
+
+	now := time.Now()
+	t.stages[stage] += now.Sub(t.mark)
+	t.mark = now
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_RecorderEndFrame(t *testing.T) {
+	r := NewRecorder()
+	r.SetThreads(2)
+
+	start := time.Now()
+	r.frameStart = start
+	r.threadStages[0][STAGE_FLOOR] = 2 * time.Millisecond
+	r.threadStages[1][STAGE_FLOOR] = 3 * time.Millisecond
+	r.threadStages[1][STAGE_WALLS] = 4 * time.Millisecond
+	r.frame.Stages[STAGE_OVERLAY] = time.Millisecond
+	r.endFrame(start.Add(8 * time.Millisecond))
+
+	// The second frame starts 2 milliseconds after the first one ended, spent presenting it.
+	r.frameStart = start.Add(10 * time.Millisecond)
+	r.frame = Frame{}
+	r.endFrame(start.Add(16 * time.Millisecond))
+
+	expected := []Frame{
+		{
+			FrameTime: 8 * time.Millisecond,
+			Stages: [StageCount]time.Duration{
+				STAGE_FLOOR:   5 * time.Millisecond,
+				STAGE_WALLS:   4 * time.Millisecond,
+				STAGE_OVERLAY: time.Millisecond,
+			},
+		},
+		{
+			FrameTime: 8 * time.Millisecond,
+			Stages: [StageCount]time.Duration{
+				STAGE_PRESENT: 2 * time.Millisecond,
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, r.window[:r.windowCount]); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+
+	if diff := cmp.Diff(expected[1], r.LastFrame()); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+
+	if fps := r.GetFps(); fps != 125.0 {
+		t.Errorf("Expected 125 FPS, got %f", fps)
+	}
+}
+
+func Test_RecorderWindow(t *testing.T) {
+	r := NewRecorder()
+	if fps := r.GetFps(); fps != 0.0 {
+		t.Errorf("Expected 0 FPS before the first frame, got %f", fps)
+	}
+
+	// Slow frames fall out of the window as faster ones are recorded.
+	for i := 0; i < WINDOW_SIZE; i++ {
+		r.record(Frame{FrameTime: 100 * time.Millisecond})
+	}
+	for i := 0; i < WINDOW_SIZE; i++ {
+		r.record(Frame{FrameTime: 10 * time.Millisecond})
+	}
+
+	if fps := r.GetFps(); fps != 100.0 {
+		t.Errorf("Expected 100 FPS, got %f", fps)
+	}
+
+	if stats := r.Stats(); stats.Frames != WINDOW_SIZE || stats.Max != 10*time.Millisecond {
+		t.Errorf("Expected %d frames of at most 10ms, got %d frames of at most %v", WINDOW_SIZE, stats.Frames, stats.Max)
+	}
+}
+
+func Test_StageTimer(t *testing.T) {
+	var r *Recorder
+	timer := r.ThreadTimer(0)
+	// A nil recorder's timer doesn't measure anything.
+	timer.Lap(STAGE_FLOOR)
+
+	r = NewRecorder()
+	timer = r.ThreadTimer(0)
+	timer.mark = timer.mark.Add(-time.Millisecond)
+	timer.Lap(STAGE_CEILING)
+
+	if got := r.threadStages[0][STAGE_CEILING]; got < time.Millisecond {
+		t.Errorf("Expected at least 1ms of ceiling, got %v", got)
+	}
+}
@@ -0,0 +1,267 @@
+package render
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+const (
+	// Minimap size, as a fraction of the frame buffer's smallest dimension, and distance in map cells it shows around
+	//	the player.
+	MINIMAP_SIZE_RATIO = 0.3
+	MINIMAP_RANGE      = 5.0
+	// Distance, in pixels, between the minimap and the edges of the frame buffer.
+	MINIMAP_MARGIN = 4
+	// Brightness of the view under the minimap's empty cells.
+	MINIMAP_BACKGROUND_INTENSITY = 0.35
+
+	// Length, in map cells, of the player's facing line and of the edges of the field of view cone.
+	MAP_FACING_LENGTH = 1.0
+	MAP_FOV_LENGTH    = 3.0
+
+	MAP_WALL_COLOR   = 0xFFB0B0B0
+	MAP_DOOR_COLOR   = 0xFF2070D0
+	MAP_PLAYER_COLOR = 0xFF00FF00
+	MAP_FACING_COLOR = 0xFF00FFFF
+	MAP_FOV_COLOR    = 0xFF008080
+	MAP_BORDER_COLOR = 0xFFFFFFFF
+)
+
+// automap keeps track of the map cells the player has seen, from the cells hit by each frame's rays.
+type automap struct {
+	gameMap [][]int
+	width   int
+	height  int
+	seen    []bool
+
+	// Cell hit by each column's ray during the frame being drawn, as an index in seen, -1 when nothing was hit. Columns
+	//	are written by the rendering threads, the cells are only marked as seen once the frame is drawn.
+	columnHits []int
+}
+
+func newAutomap(gameMap [][]int, fbWidth int) *automap {
+	height := len(gameMap)
+	width := 0
+	if height > 0 {
+		width = len(gameMap[0])
+	}
+
+	a := &automap{
+		gameMap: gameMap,
+		width:   width,
+		height:  height,
+		seen:    make([]bool, width*height),
+	}
+	a.resize(fbWidth)
+
+	return a
+}
+
+func (a *automap) resize(fbWidth int) {
+	a.columnHits = make([]int, fbWidth)
+	for x := range a.columnHits {
+		a.columnHits[x] = -1
+	}
+}
+
+// recordHit records the cell hit by a column's ray. Safe to call concurrently for different columns.
+func (a *automap) recordHit(x, cellX, cellY int) {
+	a.columnHits[x] = -1
+	if a.isInMap(cellX, cellY) {
+		a.columnHits[x] = cellX + cellY*a.width
+	}
+}
+
+// markSeenCells marks the cells hit during the last frame as seen.
+func (a *automap) markSeenCells() {
+	for _, cell := range a.columnHits {
+		if cell >= 0 {
+			a.seen[cell] = true
+		}
+	}
+}
+
+func (a *automap) isSeen(cellX, cellY int) bool {
+	return a.isInMap(cellX, cellY) && a.seen[cellX+cellY*a.width]
+}
+
+func (a *automap) isWall(cellX, cellY int) bool {
+	return a.isInMap(cellX, cellY) && cellX < len(a.gameMap[cellY]) && a.gameMap[cellY][cellX] > 0
+}
+
+func (a *automap) isInMap(cellX, cellY int) bool {
+	return cellX >= 0 && cellY >= 0 && cellX < a.width && cellY < a.height
+}
+
+// mapView maps a rectangle of the frame buffer, in pixels from the top left of the screen, to the world. The origin is
+// the world position at the top left of the view and the scale is in pixels per map cell.
+type mapView struct {
+	left   int
+	top    int
+	width  int
+	height int
+
+	originX float64
+	originY float64
+	scale   float64
+}
+
+func (v mapView) toScreen(x, y float64) (float64, float64) {
+	return float64(v.left) + (x-v.originX)*v.scale, float64(v.top) + (y-v.originY)*v.scale
+}
+
+func (v mapView) contains(px, py int) bool {
+	return px >= v.left && py >= v.top && px < v.left+v.width && py < v.top+v.height
+}
+
+// drawMapOverlay draws the map selected by the snapshot's map mode over the view.
+func (r *Renderer) drawMapOverlay() {
+	switch r.snapshot.MapMode {
+	case data.MAP_MODE_MINIMAP:
+		r.drawMinimap()
+	case data.MAP_MODE_AUTOMAP:
+		r.drawAutomap()
+	}
+}
+
+// drawMinimap draws the whole map around the player, north up, in the top right corner of the screen.
+func (r *Renderer) drawMinimap() {
+	fbWidth := r.config.GetFbWidth()
+	fbHeight := r.config.GetFbHeight()
+
+	// Tiny frame buffers leave no room for the margin, the minimap shrinks to still fit.
+	size := int(float64(min(fbWidth, fbHeight)) * MINIMAP_SIZE_RATIO)
+	size = min(size, fbWidth-MINIMAP_MARGIN, fbHeight-MINIMAP_MARGIN)
+	if size <= 0 {
+		return
+	}
+
+	player := r.snapshot.Player
+	view := mapView{
+		left:    fbWidth - size - MINIMAP_MARGIN,
+		top:     MINIMAP_MARGIN,
+		width:   size,
+		height:  size,
+		originX: player.PlayerX - MINIMAP_RANGE,
+		originY: player.PlayerY - MINIMAP_RANGE,
+		scale:   float64(size) / (2.0 * MINIMAP_RANGE),
+	}
+
+	r.drawMapCells(view, false, MINIMAP_BACKGROUND_INTENSITY)
+	r.drawMapPlayer(view)
+
+	right := float64(view.left + view.width - 1)
+	bottom := float64(view.top + view.height - 1)
+	r.drawMapLine(view, float64(view.left), float64(view.top), right, float64(view.top), MAP_BORDER_COLOR)
+	r.drawMapLine(view, float64(view.left), bottom, right, bottom, MAP_BORDER_COLOR)
+	r.drawMapLine(view, float64(view.left), float64(view.top), float64(view.left), bottom, MAP_BORDER_COLOR)
+	r.drawMapLine(view, right, float64(view.top), right, bottom, MAP_BORDER_COLOR)
+}
+
+// drawAutomap draws the cells seen so far over the whole screen, the map being scaled to fit.
+func (r *Renderer) drawAutomap() {
+	if r.automap.width == 0 || r.automap.height == 0 {
+		return
+	}
+
+	fbWidth := r.config.GetFbWidth()
+	fbHeight := r.config.GetFbHeight()
+	scale := min(float64(fbWidth)/float64(r.automap.width), float64(fbHeight)/float64(r.automap.height))
+
+	// Centre the map on the screen.
+	view := mapView{
+		width:   fbWidth,
+		height:  fbHeight,
+		originX: float64(r.automap.width)/2.0 - float64(fbWidth)/(2.0*scale),
+		originY: float64(r.automap.height)/2.0 - float64(fbHeight)/(2.0*scale),
+		scale:   scale,
+	}
+
+	r.drawMapCells(view, true, 0.0)
+	r.drawMapPlayer(view)
+}
+
+// drawMapCells fills a view with the map's walls and doors. Only seen cells are drawn when seenOnly is set. The view
+// shows through empty cells, darkened by the background intensity.
+func (r *Renderer) drawMapCells(view mapView, seenOnly bool, backgroundIntensity float64) {
+	for py := view.top; py < view.top+view.height; py++ {
+		worldY := view.originY + (float64(py-view.top)+0.5)/view.scale
+		cellY := int(math.Floor(worldY))
+
+		for px := view.left; px < view.left+view.width; px++ {
+			worldX := view.originX + (float64(px-view.left)+0.5)/view.scale
+			cellX := int(math.Floor(worldX))
+
+			fbDst := r.mapPixel(px, py)
+			if seenOnly && !r.automap.isSeen(cellX, cellY) {
+				*fbDst = shadeColor(*fbDst, backgroundIntensity)
+				continue
+			}
+
+			if _, ok := r.snapshot.GetDoorState(worldX, worldY); ok {
+				*fbDst = MAP_DOOR_COLOR
+			} else if r.automap.isWall(cellX, cellY) {
+				*fbDst = MAP_WALL_COLOR
+			} else {
+				*fbDst = shadeColor(*fbDst, backgroundIntensity)
+			}
+		}
+	}
+}
+
+// drawMapPlayer draws the player's field of view cone, facing direction and position.
+func (r *Renderer) drawMapPlayer(view mapView) {
+	player := r.snapshot.Player
+	x, y := view.toScreen(player.PlayerX, player.PlayerY)
+
+	// Y grows downward on the screen, as on the map.
+	edge := func(angle, length float64) (float64, float64) {
+		rad := angle * math.Pi / 180.0
+		return x + math.Cos(rad)*length*view.scale, y - math.Sin(rad)*length*view.scale
+	}
+
+	halfFov := r.config.GetFieldOfView() / 2.0
+	leftX, leftY := edge(player.PlayerAngle+halfFov, MAP_FOV_LENGTH)
+	rightX, rightY := edge(player.PlayerAngle-halfFov, MAP_FOV_LENGTH)
+	r.drawMapLine(view, x, y, leftX, leftY, MAP_FOV_COLOR)
+	r.drawMapLine(view, x, y, rightX, rightY, MAP_FOV_COLOR)
+
+	facingX, facingY := edge(player.PlayerAngle, MAP_FACING_LENGTH)
+	r.drawMapLine(view, x, y, facingX, facingY, MAP_FACING_COLOR)
+
+	px := int(math.Floor(x))
+	py := int(math.Floor(y))
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if view.contains(px+dx, py+dy) {
+				*r.mapPixel(px+dx, py+dy) = MAP_PLAYER_COLOR
+			}
+		}
+	}
+}
+
+// drawMapLine draws a line between two screen positions, clipped to the view.
+func (r *Renderer) drawMapLine(view mapView, x0, y0, x1, y1 float64, color uint32) {
+	steps := int(math.Ceil(max(math.Abs(x1-x0), math.Abs(y1-y0))))
+
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+
+		px := int(math.Floor(x0 + (x1-x0)*t))
+		py := int(math.Floor(y0 + (y1-y0)*t))
+		if view.contains(px, py) {
+			*r.mapPixel(px, py) = color
+		}
+	}
+}
+
+// mapPixel returns the frame buffer pixel at the given screen position, from the top left of the screen.
+func (r *Renderer) mapPixel(px, py int) *uint32 {
+	fbIndex := (px + (r.config.GetFbHeight()-1-py)*r.config.GetFbWidth()) << 2
+	return (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+func Test_AutomapSeenCells(t *testing.T) {
+	gameMap := [][]int{
+		{1, 1, 1, 1},
+		{1, 0, 0, 1},
+		{1, 1, 1, 1},
+	}
+
+	testCases := []struct {
+		name     string
+		hits     [][2]int // Cell hit by each column.
+		expected []bool
+	}{
+		{
+			name:     "nothing_hit",
+			hits:     [][2]int{{-1, -1}, {-1, -1}, {-1, -1}},
+			expected: make([]bool, 12),
+		},
+		{
+			name: "walls_hit",
+			hits: [][2]int{{0, 1}, {0, 1}, {2, 2}},
+			expected: []bool{
+				false, false, false, false,
+				true, false, false, false,
+				false, false, true, false,
+			},
+		},
+		{
+			name: "outside_of_the_map",
+			hits: [][2]int{{4, 0}, {3, -1}, {3, 2}},
+			expected: []bool{
+				false, false, false, false,
+				false, false, false, false,
+				false, false, false, true,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newAutomap(gameMap, len(tc.hits))
+			for x, hit := range tc.hits {
+				a.recordHit(x, hit[0], hit[1])
+			}
+			a.markSeenCells()
+
+			if diff := cmp.Diff(tc.expected, a.seen); diff != "" {
+				t.Errorf("Test failed\n%s\n", diff)
+			}
+		})
+	}
+}
+
+// The minimap must stay within frame buffers too small to hold it along with its margin.
+func Test_DrawMinimapTinyFrameBuffer(t *testing.T) {
+	gameMap := [][]int{
+		{1, 1, 1},
+		{1, 0, 1},
+		{1, 1, 1},
+	}
+
+	sizes := [][2]int{{1, 1}, {4, 4}, {5, 5}, {6, 100}, {100, 6}, {20, 20}}
+	for _, size := range sizes {
+		width, height := size[0], size[1]
+		r := Renderer{
+			config:      config.NewRenderConfiguration(width, height, 60.0, false),
+			frameBuffer: make([]uint8, width*height*4),
+			automap:     newAutomap(gameMap, width),
+			snapshot: data.WorldSnapshot{
+				Player:  data.PlayerCoordData{PlayerX: 1.5, PlayerY: 1.5},
+				MapMode: data.MAP_MODE_MINIMAP,
+			},
+		}
+
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					t.Errorf("Drawing the minimap on a %dx%d frame buffer panicked: %v", width, height, err)
+				}
+			}()
+			r.drawMapOverlay()
+		}()
+	}
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"math"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+// fogModel blends colours toward the fog colour with distance.
+type fogModel struct {
+	mode    string
+	red     float64
+	green   float64
+	blue    float64
+	start   float64
+	end     float64
+	density float64
+}
+
+func newFogModel(fogData data.FogData) fogModel {
+	return fogModel{
+		mode:    fogData.Mode,
+		red:     float64(fogData.Color[0]),
+		green:   float64(fogData.Color[1]),
+		blue:    float64(fogData.Color[2]),
+		start:   fogData.Start,
+		end:     fogData.End,
+		density: fogData.Density,
+	}
+}
+
+// computeFogFactor returns how much of the fog colour is seen at a distance, from 0 (clear) to 1 (only fog).
+func (f fogModel) computeFogFactor(distance float64) float64 {
+	switch f.mode {
+	case data.FOG_LINEAR:
+		if f.end <= f.start {
+			return 0.0
+		}
+		return math.Min(math.Max((distance-f.start)/(f.end-f.start), 0.0), 1.0)
+	case data.FOG_EXPONENTIAL:
+		return 1.0 - math.Exp(-f.density*math.Max(distance, 0.0))
+	}
+
+	return 0.0
+}
+
+// applyFog blends a colour toward the fog colour, alpha is kept as is.
+func (f fogModel) applyFog(colorComponent uint32, factor float64) uint32 {
+	if factor <= 0.0 {
+		return colorComponent
+	}
+
+	R := uint32(float64(colorComponent&0xFF) + (f.red-float64(colorComponent&0xFF))*factor)
+	G := uint32(float64(colorComponent>>8&0xFF) + (f.green-float64(colorComponent>>8&0xFF))*factor)
+	B := uint32(float64(colorComponent>>16&0xFF) + (f.blue-float64(colorComponent>>16&0xFF))*factor)
+	A := colorComponent >> 24 & 0xFF
+
+	return A<<24 | B<<16 | G<<8 | R
+}
+
+// precomputeRowFogFactors computes the fog factor of the floor and ceiling rows, indexed by the number of rows away from
+// the horizon. Floor and ceiling rows at the same distance from the horizon are at the same distance from the player.
+func (r *Renderer) precomputeRowFogFactors() {
+	height := r.config.GetFbHeight()
+	halfHeight := height >> 1
+	r.rowFogFactors = make([]float64, halfHeight+1)
+
+	for row := range r.rowFogFactors {
+		distance := float64(height) / (2.0 * (float64(row) + 0.5))
+		r.rowFogFactors[row] = r.fog.computeFogFactor(distance)
+	}
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+func Test_FogModelComputeFogFactor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fog      data.FogData
+		distance float64
+		expected float64
+	}{
+		{
+			name:     "disabled",
+			fog:      data.FogData{},
+			distance: 100.0,
+			expected: 0.0,
+		},
+		{
+			name:     "linear_before_start",
+			fog:      data.FogData{Mode: data.FOG_LINEAR, Start: 2.0, End: 6.0},
+			distance: 1.0,
+			expected: 0.0,
+		},
+		{
+			name:     "linear_halfway",
+			fog:      data.FogData{Mode: data.FOG_LINEAR, Start: 2.0, End: 6.0},
+			distance: 4.0,
+			expected: 0.5,
+		},
+		{
+			name:     "linear_past_end",
+			fog:      data.FogData{Mode: data.FOG_LINEAR, Start: 2.0, End: 6.0},
+			distance: 10.0,
+			expected: 1.0,
+		},
+		{
+			name:     "exponential",
+			fog:      data.FogData{Mode: data.FOG_EXPONENTIAL, Density: 0.5},
+			distance: 2.0,
+			expected: 0.632120559,
+		},
+		{
+			name:     "exponential_at_player",
+			fog:      data.FogData{Mode: data.FOG_EXPONENTIAL, Density: 0.5},
+			distance: 0.0,
+			expected: 0.0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newFogModel(tc.fog).computeFogFactor(tc.distance)
+
+			if !approximately(got, tc.expected) {
+				t.Errorf("Expected %f, got %f", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_FogModelApplyFog(t *testing.T) {
+	fog := newFogModel(data.FogData{Mode: data.FOG_LINEAR, Color: [3]int{0x10, 0x20, 0x30}, End: 1.0})
+
+	testCases := []struct {
+		name     string
+		color    uint32
+		factor   float64
+		expected uint32
+	}{
+		{
+			name:     "clear",
+			color:    0xFF706050,
+			factor:   0.0,
+			expected: 0xFF706050,
+		},
+		{
+			name:     "half",
+			color:    0xFF706050,
+			factor:   0.5,
+			expected: 0xFF504030,
+		},
+		{
+			name:     "full_keeps_alpha",
+			color:    0x80706050,
+			factor:   1.0,
+			expected: 0x80302010,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fog.applyFog(tc.color, tc.factor); got != tc.expected {
+				t.Errorf("Expected %08X, got %08X", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package render
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/game"
+	"github.com/rebay1982/redcaster/internal/texture"
+)
+
+// Regenerate the golden images with: go test ./internal/render -run Golden -update
+var updateGolden = flag.Bool("update", false, "Regenerate the renderer's golden images.")
+
+const (
+	GOLDEN_FB_WIDTH  = 160
+	GOLDEN_FB_HEIGHT = 120
+
+	// Maximum difference on a single colour channel for a pixel to still match.
+	GOLDEN_CHANNEL_TOLERANCE = 2
+	// Fraction of pixels allowed to mismatch before failing.
+	GOLDEN_MAX_MISMATCH_RATIO = 0.001
+)
+
+// renderGoldenFrame renders a single frame of a level headlessly. A max ray distance of 0 uses the default.
+func renderGoldenFrame(t *testing.T, levelData data.LevelData, mapMode data.MapMode, maxDistance float64,
+	threads int) []uint8 {
+	t.Helper()
+
+	renderConfig := config.NewRenderConfiguration(GOLDEN_FB_WIDTH, GOLDEN_FB_HEIGHT, 60.0, false)
+	renderConfig.SetThreads(threads)
+	if maxDistance > 0.0 {
+		renderConfig.SetMaxRayDistance(maxDistance)
+	}
+
+	game := game.NewGame(gameConfig, levelData, nil)
+	game.SetMapMode(mapMode)
+	textureManager := texture.NewTextureManager(renderConfig, levelData)
+	renderer := NewRenderer(renderConfig, &game, &textureManager, levelData)
+	defer renderer.Close()
+
+	// Copy, the frame buffer is reused by the renderer.
+	frameBuffer := renderer.Draw()
+	return append([]uint8{}, frameBuffer...)
+}
+
+func readGoldenImage(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+func writeGoldenImage(filename string, img image.Image) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, img)
+}
+
+// compareGoldenImages compares two images pixel by pixel. Returns the number of mismatching pixels and an image where
+// they're highlighted in red over a dimmed copy of the expected image.
+func compareGoldenImages(want, got image.Image) (int, *image.RGBA) {
+	bounds := want.Bounds()
+	diff := image.NewRGBA(bounds)
+	mismatches := 0
+
+	withinTolerance := func(a, b uint32) bool {
+		delta := int(a>>8) - int(b>>8)
+		return delta >= -GOLDEN_CHANNEL_TOLERANCE && delta <= GOLDEN_CHANNEL_TOLERANCE
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			wR, wG, wB, wA := want.At(x, y).RGBA()
+			gR, gG, gB, gA := got.At(x, y).RGBA()
+
+			if withinTolerance(wR, gR) && withinTolerance(wG, gG) && withinTolerance(wB, gB) && withinTolerance(wA, gA) {
+				gray := uint8(((wR + wG + wB) / 3) >> 10)
+				diff.Set(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 0xFF})
+			} else {
+				mismatches++
+				diff.Set(x, y, color.RGBA{R: 0xFF, A: 0xFF})
+			}
+		}
+	}
+
+	return mismatches, diff
+}
+
+func Test_RendererGolden(t *testing.T) {
+	testCases := []struct {
+		name    string
+		level   string
+		camera  data.PlayerCoordData
+		mapMode data.MapMode
+		// Max ray distance, the default when 0.
+		maxDistance float64
+	}{
+		// Facing every wall orientation catches mirrored textures on any face.
+		{
+			name:   "room_facing_east",
+			level:  "room.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 4.5, PlayerAngle: 0.0},
+		},
+		{
+			name:   "room_facing_north",
+			level:  "room.json",
+			camera: data.PlayerCoordData{PlayerX: 4.5, PlayerY: 6.5, PlayerAngle: 90.0},
+		},
+		{
+			name:   "room_facing_west",
+			level:  "room.json",
+			camera: data.PlayerCoordData{PlayerX: 6.5, PlayerY: 4.5, PlayerAngle: 180.0},
+		},
+		{
+			name:   "room_facing_south",
+			level:  "room.json",
+			camera: data.PlayerCoordData{PlayerX: 4.5, PlayerY: 1.5, PlayerAngle: 270.0},
+		},
+		{
+			name:   "room_sprite_and_pillar",
+			level:  "room.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 1.5, PlayerAngle: 315.0},
+		},
+		// Rays stopping short of the walls leave the floor and ceiling visible.
+		{
+			name:        "room_short_rays",
+			level:       "room.json",
+			camera:      data.PlayerCoordData{PlayerX: 1.5, PlayerY: 4.5, PlayerAngle: 0.0},
+			maxDistance: 1.0,
+		},
+		// Standing on the grid lines, the walls to the north and west are at a distance of 0.
+		{
+			name:        "room_against_walls",
+			level:       "room.json",
+			camera:      data.PlayerCoordData{PlayerX: 1.0, PlayerY: 1.0, PlayerAngle: 0.0},
+			maxDistance: 1.0,
+		},
+		{
+			name:   "room_wall_faces",
+			level:  "room-walls.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 1.5, PlayerAngle: 315.0},
+		},
+		{
+			name:   "room_wall_faces_opposite",
+			level:  "room-walls.json",
+			camera: data.PlayerCoordData{PlayerX: 5.5, PlayerY: 5.5, PlayerAngle: 135.0},
+		},
+		{
+			name:   "corridors_door",
+			level:  "corridors.json",
+			camera: data.PlayerCoordData{PlayerX: 4.5, PlayerY: 7.5, PlayerAngle: 90.0},
+		},
+		{
+			name:   "corridors_diagonal",
+			level:  "corridors.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 8.5, PlayerAngle: 30.0},
+		},
+		{
+			name:   "corridors_shading",
+			level:  "corridors-shaded.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 8.5, PlayerAngle: 30.0},
+		},
+		{
+			name:   "room_fog_exponential",
+			level:  "room-fog.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 1.5, PlayerAngle: 315.0},
+		},
+		{
+			name:   "corridors_fog_linear",
+			level:  "corridors-fog.json",
+			camera: data.PlayerCoordData{PlayerX: 1.5, PlayerY: 8.5, PlayerAngle: 30.0},
+		},
+		{
+			name:    "corridors_minimap",
+			level:   "corridors.json",
+			camera:  data.PlayerCoordData{PlayerX: 4.5, PlayerY: 7.5, PlayerAngle: 60.0},
+			mapMode: data.MAP_MODE_MINIMAP,
+		},
+		// A single frame only reveals the cells hit by its rays.
+		{
+			name:    "corridors_automap",
+			level:   "corridors.json",
+			camera:  data.PlayerCoordData{PlayerX: 4.5, PlayerY: 7.5, PlayerAngle: 60.0},
+			mapMode: data.MAP_MODE_AUTOMAP,
+		},
+	}
+
+	// The test levels share the demo textures.
+	loader := data.NewDataLoader(filepath.Join("..", "..", "assets", "demo"))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			levelData, err := loader.LoadLevelData(filepath.Join("testdata", "levels", tc.level))
+			if err != nil {
+				t.Fatalf("Failed to load level %s: %v", tc.level, err)
+			}
+			levelData.PlayerCoordData = tc.camera
+
+			frameBuffer := renderGoldenFrame(t, levelData, tc.mapMode, tc.maxDistance, 1)
+			got := FrameBufferToImage(frameBuffer, GOLDEN_FB_WIDTH, GOLDEN_FB_HEIGHT)
+			goldenFile := filepath.Join("testdata", "golden", tc.name+".png")
+
+			if *updateGolden {
+				if err := writeGoldenImage(goldenFile, got); err != nil {
+					t.Fatalf("Failed to write golden image %s: %v", goldenFile, err)
+				}
+				return
+			}
+
+			// Rendering in parallel must not change a single pixel.
+			if diff := cmp.Diff(frameBuffer, renderGoldenFrame(t, levelData, tc.mapMode, tc.maxDistance, 3)); diff != "" {
+				t.Errorf("Multi-threaded rendering differs from single threaded rendering")
+			}
+
+			want, err := readGoldenImage(goldenFile)
+			if err != nil {
+				t.Fatalf("Failed to read golden image %s, regenerate with -update: %v", goldenFile, err)
+			}
+
+			if want.Bounds() != got.Bounds() {
+				t.Fatalf("Expected image bounds %v, got %v", want.Bounds(), got.Bounds())
+			}
+
+			mismatches, diff := compareGoldenImages(want, got)
+			maxMismatches := int(GOLDEN_MAX_MISMATCH_RATIO * float64(want.Bounds().Dx()*want.Bounds().Dy()))
+			if mismatches > maxMismatches {
+				gotFile := filepath.Join("testdata", "golden", tc.name+"_got.png")
+				diffFile := filepath.Join("testdata", "golden", tc.name+"_diff.png")
+				writeGoldenImage(gotFile, got)
+				writeGoldenImage(diffFile, diff)
+
+				t.Errorf("%d pixels differ from %s (max %d), see %s and %s",
+					mismatches, goldenFile, maxMismatches, gotFile, diffFile)
+			}
+		})
+	}
+}
@@ -5,6 +5,29 @@ type coordinates struct {
 	y float64
 }
 
+// rayAxis selects which grid lines are considered when casting a ray.
+type rayAxis int
+
+const (
+	rayAxisVertical rayAxis = 1 << iota
+	rayAxisHorizontal
+	rayAxisBoth = rayAxisVertical | rayAxisHorizontal
+)
+
+// wallFace identifies the side of a wall cell a ray collided with. Faces are named after the direction they are facing,
+// north being towards the top of the map (decreasing Y).
+type wallFace int
+
+const (
+	wallFaceNone wallFace = iota
+	wallFaceNorth
+	wallFaceSouth
+	wallFaceEast
+	wallFaceWest
+
+	wallFaceCount
+)
+
 type collisionDetail struct {
 	rayStart  coordinates
 	rayEnd    coordinates
@@ -13,6 +36,15 @@ type collisionDetail struct {
 
 	wallType        int
 	wallOrientation int
+	wallFace        wallFace
+	// Map cell hit by the ray, -1 when the ray didn't hit anything.
+	cellX int
+	cellY int
+
+	// Offset substracted from the texture coordinate, used by sliding doors.
+	textureOffset float64
+	// Doors use their texture id as is, they aren't looked up in the wall definitions.
+	door bool
 }
 
 type wallRenderingDetail struct {
@@ -20,6 +52,20 @@ type wallRenderingDetail struct {
 	wallDistance    float64
 	wallTextureId   int
 	wallOrientation int
+	wallFace        wallFace
+	cellX           int
+	cellY           int
 
 	rayCollisionTextureCoordinate float64
 }
+
+type spriteRenderingDetail struct {
+	spriteDistance  float64
+	spriteTextureId int
+
+	// Screen space bounds of the sprite, in pixels from the top left of the screen. They can exceed the frame buffer.
+	screenLeft   int
+	screenTop    int
+	screenWidth  int
+	screenHeight int
+}
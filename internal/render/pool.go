@@ -0,0 +1,79 @@
+package render
+
+import (
+	"sync"
+)
+
+const (
+	// Column strips per rendering thread. Strips are smaller than an even split so threads that finish early pick up
+	//	more work, some columns being a lot more costly than others (close walls, sprites).
+	STRIPS_PER_THREAD = 4
+)
+
+// columnStrip is a range of frame buffer columns [start, end) rendered by a single thread.
+type columnStrip struct {
+	start int
+	end   int
+
+	// Projected sprites, sorted from furthest to closest.
+	sprites []spriteRenderingDetail
+}
+
+// renderPool is a pool of rendering threads. Each thread is identified by an index, used to pick its own scratch
+// buffers.
+type renderPool struct {
+	strips chan columnStrip
+	wg     sync.WaitGroup
+	// Running threads, waited on when the pool stops.
+	threads sync.WaitGroup
+}
+
+func newRenderPool(threads int, draw func(thread int, strip columnStrip)) *renderPool {
+	p := &renderPool{
+		strips: make(chan columnStrip, threads*STRIPS_PER_THREAD),
+	}
+
+	p.threads.Add(threads)
+	for t := 0; t < threads; t++ {
+		go func(thread int) {
+			defer p.threads.Done()
+			for strip := range p.strips {
+				draw(thread, strip)
+				p.wg.Done()
+			}
+		}(t)
+	}
+
+	return p
+}
+
+// computeColumnStrips splits the frame buffer columns into strips for the given number of threads.
+func computeColumnStrips(width, threads int) []columnStrip {
+	count := min(threads*STRIPS_PER_THREAD, width)
+	strips := make([]columnStrip, 0, count)
+
+	for i := 0; i < count; i++ {
+		strips = append(strips, columnStrip{
+			start: i * width / count,
+			end:   (i + 1) * width / count,
+		})
+	}
+
+	return strips
+}
+
+// run renders all strips and waits for them to be done.
+func (p *renderPool) run(strips []columnStrip, sprites []spriteRenderingDetail) {
+	p.wg.Add(len(strips))
+	for _, strip := range strips {
+		strip.sprites = sprites
+		p.strips <- strip
+	}
+	p.wg.Wait()
+}
+
+// stop terminates the pool's threads and waits for them to exit. The pool can't run strips afterwards.
+func (p *renderPool) stop() {
+	close(p.strips)
+	p.threads.Wait()
+}
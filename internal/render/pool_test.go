@@ -0,0 +1,76 @@
+package render
+
+import (
+	"testing"
+)
+
+func Test_ComputeColumnStrips(t *testing.T) {
+	testCases := []struct {
+		name      string
+		width     int
+		threads   int
+		wantCount int
+	}{
+		{
+			name:      "single_thread",
+			width:     640,
+			threads:   1,
+			wantCount: STRIPS_PER_THREAD,
+		},
+		{
+			name:      "uneven_split",
+			width:     641,
+			threads:   3,
+			wantCount: 3 * STRIPS_PER_THREAD,
+		},
+		{
+			name:      "more_strips_than_columns",
+			width:     5,
+			threads:   8,
+			wantCount: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := computeColumnStrips(tc.width, tc.threads)
+
+			if len(got) != tc.wantCount {
+				t.Fatalf("Expected %d strips, got %d", tc.wantCount, len(got))
+			}
+
+			// Strips must cover every column exactly once, in order.
+			next := 0
+			for _, strip := range got {
+				if strip.start != next || strip.end <= strip.start {
+					t.Errorf("Unexpected strip [%d, %d), expected it to start at %d", strip.start, strip.end, next)
+				}
+				next = strip.end
+			}
+
+			if next != tc.width {
+				t.Errorf("Expected strips to end at column %d, got %d", tc.width, next)
+			}
+		})
+	}
+}
+
+func Test_RenderPoolStop(t *testing.T) {
+	// Each thread only writes its own counter.
+	drawn := make([]int, 4)
+	p := newRenderPool(len(drawn), func(thread int, strip columnStrip) {
+		drawn[thread]++
+	})
+
+	p.run(computeColumnStrips(64, len(drawn)), nil)
+	// Returns once every thread has exited.
+	p.stop()
+
+	total := 0
+	for _, count := range drawn {
+		total += count
+	}
+	if expected := len(drawn) * STRIPS_PER_THREAD; total != expected {
+		t.Errorf("Expected %d strips drawn, got %d", expected, total)
+	}
+}
@@ -1,23 +1,34 @@
 package render
 
 import (
-	"fmt"
 	"math"
+	"time"
 	"unsafe"
 
 	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/metrics"
+)
+
+const (
+	// Closest distance a wall is drawn at, in map units. A player standing on a grid line next to a wall would see it at
+	//	a distance of 0, with an infinite height.
+	MIN_WALL_DISTANCE = 0.001
 )
 
 type TextureManager interface {
 	Reconfigure(config config.RenderConfiguration)
-	GetTextureVertical(textureId int, renderHeight int, texColumnCoord float64) []uint8
-	GetSkyTextureVertical(rAngle float64) []uint8
+	GetTextureVertical(thread int, textureId int, renderHeight int, texColumnCoord float64) []uint8
+	GetSkyTextureVertical(thread int, rAngle float64) []uint8
+	GetTexturePixel(textureId int, texXCoord, texYCoord float64) uint32
+	SetAnimationTime(elapsed time.Duration)
 }
 
+// GameManager gives the renderer access to the world. Implementations must be safe to call while the game is updating.
+// The map is static, everything that moves, doors included, comes from the world snapshot.
 type GameManager interface {
-	GetPlayerCoords() data.PlayerCoordData
-	CheckWallCollision(x, y float64) (bool, int)
+	GetWorldSnapshot() data.WorldSnapshot
+	CheckMapCollision(x, y float64) (bool, int)
 }
 
 type Renderer struct {
@@ -26,37 +37,90 @@ type Renderer struct {
 	frameBuffer   []uint8
 	rAngleOffsets []float64
 	ambientLight  float64
+	fog           fogModel
+	wallShading   [wallFaceCount]float64
+	// Texture ids of each face, by wall type. Wall types without a definition use their type as texture id.
+	wallTextures map[int][wallFaceCount]int
+	// Fog factors of floor and ceiling rows, by distance in rows from the horizon.
+	rowFogFactors []float64
+	// Per cell floor and ceiling texture ids, nil when the level doesn't define them.
+	floorTextures   [][]int
+	ceilingTextures [][]int
+	// Perpendicular distance to the wall drawn in each column, used to clip sprites behind walls.
+	depthBuffer   []float64
+	spriteDetails []spriteRenderingDetail
+	automap       *automap
+	// World as seen by the frame being drawn.
+	snapshot data.WorldSnapshot
 	// TODO: Create a rendering memory manager
 	textureManager TextureManager
-	metrics        *fpsMetrics // Needs to be, and a pointer, else we're always recreating a new instance on Draw.
+	pool           *renderPool
+	strips         []columnStrip
+	// Records the time spent in each stage of the frame, nil when metrics are disabled.
+	metrics *metrics.Recorder
 }
 
-// NewRenderer The game is a pointer because we want updates (from game) to the player position to be accessible.
+// NewRenderer The game is a pointer because we want updates (from game) to be accessible through its world snapshots.
 func NewRenderer(config config.RenderConfiguration, gMngr GameManager, tMngr TextureManager, levelData data.LevelData) *Renderer {
 	r := &Renderer{
 		gameManager:  gMngr,
 		config:       config,
 		frameBuffer:  make([]uint8, config.ComputeFrameBufferSize(), config.ComputeFrameBufferSize()),
 		ambientLight: levelData.AmbientLight,
+		fog:          newFogModel(levelData.Fog),
+		wallShading:  newWallShading(levelData.WallShading),
+		wallTextures: newWallTextures(levelData.Walls),
+
+		floorTextures:   levelData.FloorTextures,
+		ceilingTextures: levelData.CeilingTextures,
+		depthBuffer:     make([]float64, config.GetFbWidth()),
+		spriteDetails:   make([]spriteRenderingDetail, 0, len(levelData.Sprites)),
+		automap:         newAutomap(levelData.Map, config.GetFbWidth()),
+		snapshot:        gMngr.GetWorldSnapshot(),
 	}
 	r.precomputeRayAngleOffsets()
+	r.precomputeRowFogFactors()
 	r.textureManager = tMngr
+	r.startRenderPool()
+
+	return r
+}
+
+// startRenderPool starts the rendering threads, the renderer is captured as a pointer so they always see its latest
+// configuration.
+func (r *Renderer) startRenderPool() {
+	r.strips = computeColumnStrips(r.config.GetFbWidth(), r.config.GetThreads())
+	r.pool = newRenderPool(r.config.GetThreads(), func(thread int, strip columnStrip) {
+		r.drawStrip(thread, strip)
+	})
 
-	if config.IsDisplayFpsEnabled() {
-		r.metrics = &fpsMetrics{}
+	if r.metrics != nil {
+		r.metrics.SetThreads(r.config.GetThreads())
 	}
+}
 
-	return r
+// SetMetricsRecorder makes the renderer time the stages of the frames it draws. The recorder's frames are begun and
+// ended by the caller, around Draw and whatever it draws over the frame.
+func (r *Renderer) SetMetricsRecorder(recorder *metrics.Recorder) {
+	r.metrics = recorder
+	r.metrics.SetThreads(r.config.GetThreads())
+}
+
+// Close stops the rendering threads. The renderer can't draw afterwards.
+func (r *Renderer) Close() {
+	r.pool.stop()
 }
 
 func (r *Renderer) ReconfigureRenderer(config config.RenderConfiguration) {
 	r.config = config
 	r.frameBuffer = make([]uint8, config.ComputeFrameBufferSize(), config.ComputeFrameBufferSize())
+	r.depthBuffer = make([]float64, config.GetFbWidth())
+	r.automap.resize(config.GetFbWidth())
 	r.precomputeRayAngleOffsets()
+	r.precomputeRowFogFactors()
 
-	if config.IsDisplayFpsEnabled() {
-		r.metrics = &fpsMetrics{}
-	}
+	r.pool.stop()
+	r.startRenderPool()
 
 	r.textureManager.Reconfigure(config)
 }
@@ -76,14 +140,53 @@ func (r *Renderer) precomputeRayAngleOffsets() {
 }
 
 func (r Renderer) applyLightingEffects(colorComponent uint32) uint32 {
-	R := uint32(float64(colorComponent&0xFF) * r.ambientLight)
-	G := uint32(float64(colorComponent>>8&0xFF) * r.ambientLight)
-	B := uint32(float64(colorComponent>>16&0xFF) * r.ambientLight)
+	return shadeColor(colorComponent, r.ambientLight)
+}
+
+// shadeColor multiplies a colour's components by an intensity, alpha is kept as is.
+func shadeColor(colorComponent uint32, intensity float64) uint32 {
+	R := uint32(float64(colorComponent&0xFF) * intensity)
+	G := uint32(float64(colorComponent>>8&0xFF) * intensity)
+	B := uint32(float64(colorComponent>>16&0xFF) * intensity)
 	A := colorComponent >> 24 & 0xFF
 
 	return A<<24 | B<<16 | G<<8 | R
 }
 
+// newWallTextures maps the level's wall definitions to texture ids indexed by wall face.
+func newWallTextures(walls []data.WallData) map[int][wallFaceCount]int {
+	wallTextures := make(map[int][wallFaceCount]int, len(walls))
+
+	for _, wall := range walls {
+		resolved := wall.ResolveFaceTextures()
+		wallTextures[wall.Type] = [wallFaceCount]int{
+			wallFaceNone:  resolved.Texture,
+			wallFaceNorth: resolved.North,
+			wallFaceSouth: resolved.South,
+			wallFaceEast:  resolved.East,
+			wallFaceWest:  resolved.West,
+		}
+	}
+
+	return wallTextures
+}
+
+// newWallShading maps the level's wall shading to multipliers indexed by wall face. Levels without shading get 1 on
+// every face.
+func newWallShading(shading data.WallShadingData) [wallFaceCount]float64 {
+	if shading == (data.WallShadingData{}) {
+		return [wallFaceCount]float64{1.0, 1.0, 1.0, 1.0, 1.0}
+	}
+
+	return [wallFaceCount]float64{
+		wallFaceNone:  1.0,
+		wallFaceNorth: shading.North,
+		wallFaceSouth: shading.South,
+		wallFaceEast:  shading.East,
+		wallFaceWest:  shading.West,
+	}
+}
+
 /*
 Reference for RayAngle:
 
@@ -94,8 +197,7 @@ Reference for RayAngle:
 				 270
 */
 func (r Renderer) computeRayAngle(x int) float64 {
-	palyerCoords := r.gameManager.GetPlayerCoords()
-	pAng := palyerCoords.PlayerAngle
+	pAng := r.snapshot.Player.PlayerAngle
 
 	rayAngle := pAng + r.rAngleOffsets[x]
 
@@ -109,137 +211,223 @@ func (r Renderer) computeRayAngle(x int) float64 {
 	return rayAngle
 }
 
+// computeVerticalCollision casts a ray that only considers vertical grid lines (walls hit on their east or west face).
 func (r Renderer) computeVerticalCollision(x, y, rAngle float64) collisionDetail {
-	// Note: We don't check for rAngle == 90 or 270. This is because hen checking vertical wall collisions, the ray will
-	//       never intersect with one when it is projected at 90 or 270 degrees.
+	return r.castRay(x, y, rAngle, rayAxisVertical)
+}
 
+// computeHorizontalCollision casts a ray that only considers horizontal grid lines (walls hit on their north or south
+// face).
+func (r Renderer) computeHorizontalCollision(x, y, rAngle float64) collisionDetail {
+	return r.castRay(x, y, rAngle, rayAxisHorizontal)
+}
+
+// castRay walks the map grid from x, y in the direction of rAngle using a DDA traversal. At every step, the ray
+// advances to whichever grid line (vertical or horizontal) is the closest and checks the cell on the other side of it
+// for a wall. The traversal stops on the first wall hit, when the ray leaves the map (which is reported as a collision
+// by the game manager) or once the ray travels further than the configured max ray distance.
+//
+// The axes parameter restricts which grid lines are considered. Rays that don't hit anything report the max ray
+// distance as their length and end coordinates.
+func (r Renderer) castRay(x, y, rAngle float64, axes rayAxis) collisionDetail {
 	// Convert the angle (in degrees) to radians because that's what the math library expects.
 	rRad := rAngle * math.Pi / 180.0
-	rLength := 2048.0
-	wType := 0
+	rCos := math.Cos(rRad)
+	rSin := math.Sin(rRad)
+	maxDistance := r.config.GetMaxRayDistance()
+
+	// Step direction on each axis. The ray never crosses vertical grid lines when projected at 90 or 270 degrees and
+	//	never crosses horizontal grid lines when projected at 0 or 180 degrees. These are left at 0 and skipped.
+	stepX := 0
+	if axes&rayAxisVertical != 0 {
+		if rAngle < 90.0 || rAngle > 270.0 {
+			stepX = 1
+		} else if rAngle > 90.0 && rAngle < 270.0 {
+			stepX = -1
+		}
+	}
+
+	// 0 on the Y axis is at the top, Y decrements when the ray's angle is between 0 and 180.
+	stepY := 0
+	if axes&rayAxisHorizontal != 0 {
+		if rAngle > 0.0 && rAngle < 180.0 {
+			stepY = -1
+		} else if rAngle > 180.0 && rAngle < 360.0 {
+			stepY = 1
+		}
+	}
+
+	// First grid lines the ray will cross on each axis.
+	nextX := math.Floor(x)
+	if stepX > 0 {
+		nextX += 1.0
+	}
+	nextY := math.Floor(y)
+	if stepY > 0 {
+		nextY += 1.0
+	}
+
 	startCoords := coordinates{
 		x: x,
 		y: y,
 	}
-	endCoords := coordinates{
-		x: 2048.0,
-		y: 2048.0,
-	}
 
-	if rAngle < 90.0 || rAngle > 270 {
-		for i := 1; i < 16; i++ {
-			// Coordinates of ray FROM initial position x, y
-			rX := float64(int(x)+i) - x
-			rY := math.Tan(rRad) * rX
+	for {
+		distX := math.Inf(1)
+		if stepX != 0 {
+			distX = math.Abs((nextX - x) / rCos)
+		}
 
-			// Substract rY because 0 on the Y axis is at the top. When moving X to the right (inc), Y will decrement when the
-			//   ray's angle is between 0 and 90.
-			if collision, wall := r.gameManager.CheckWallCollision(x+rX, y-rY); collision {
-				rLength = rX / math.Cos(rRad)
-				wType = wall
-				endCoords.x = x + rX
-				endCoords.y = y - rY
+		distY := math.Inf(1)
+		if stepY != 0 {
+			distY = math.Abs((nextY - y) / rSin)
+		}
 
+		if distX <= distY {
+			if distX > maxDistance {
 				break
 			}
-		}
-	}
-
-	if rAngle > 90.0 && rAngle < 270.0 {
-		for i := 0; i < 16; i++ {
-			// Coordinates of ray FROM initial position x, y
-			rX := x - float64(int(x)-i)
-			rY := math.Tan(rRad) * rX
 
-			// Substract rX because 0 on the X axis is at the far left. When the ray's angle is between 90 and 270, X is
-			//   moving to the left thus decrementing X.
+			// The cell to check is on the left of the grid line when moving towards decreasing X.
+			cellX := nextX
+			face := wallFaceWest
+			if stepX < 0 {
+				cellX -= 1.0
+				face = wallFaceEast
+			}
 
-			// -0.001 hack on x-xR necessary because collision checking is done on integer values (ex: >= 1, < 2). Ray should
-			//   be < 1 if player is standing right next to a wall in an adjacent square.
-			if collision, wall := r.gameManager.CheckWallCollision(x-rX-0.001, y+rY); collision {
-				rLength = rX / math.Cos(rRad)
-				wType = wall
-				endCoords.x = x - rX
-				endCoords.y = y + rY
+			rY := y - distX*rSin
+			if door, ok := r.snapshot.GetDoorState(cellX, rY); ok {
+				if collision, hit := r.computeDoorCollision(x, y, rAngle, door); hit {
+					return collision
+				}
+			} else if collision, wall := r.gameManager.CheckMapCollision(cellX, rY); collision {
+				return collisionDetail{
+					rayStart:        startCoords,
+					rayEnd:          coordinates{x: nextX, y: rY},
+					rayAngle:        rAngle,
+					rayLength:       distX,
+					wallType:        wall,
+					wallOrientation: 0, // Vertical wall collision
+					wallFace:        face,
+					cellX:           int(cellX),
+					cellY:           int(math.Floor(rY)),
+				}
+			}
+			nextX += float64(stepX)
 
+		} else {
+			if distY > maxDistance {
 				break
 			}
+
+			// The cell to check is above the grid line when moving towards decreasing Y.
+			cellY := nextY
+			face := wallFaceNorth
+			if stepY < 0 {
+				cellY -= 1.0
+				face = wallFaceSouth
+			}
+
+			rX := x + distY*rCos
+			if door, ok := r.snapshot.GetDoorState(rX, cellY); ok {
+				if collision, hit := r.computeDoorCollision(x, y, rAngle, door); hit {
+					return collision
+				}
+			} else if collision, wall := r.gameManager.CheckMapCollision(rX, cellY); collision {
+				return collisionDetail{
+					rayStart:        startCoords,
+					rayEnd:          coordinates{x: rX, y: nextY},
+					rayAngle:        rAngle,
+					rayLength:       distY,
+					wallType:        wall,
+					wallOrientation: 1, // Horizontal wall collision
+					wallFace:        face,
+					cellX:           int(math.Floor(rX)),
+					cellY:           int(cellY),
+				}
+			}
+			nextY += float64(stepY)
 		}
 	}
 
 	return collisionDetail{
-		rayStart:        startCoords,
-		rayEnd:          endCoords,
-		rayAngle:        rAngle,
-		rayLength:       math.Abs(rLength),
-		wallType:        wType,
-		wallOrientation: 0, // Vertical wall collision
+		rayStart:  startCoords,
+		rayEnd:    coordinates{x: maxDistance, y: maxDistance},
+		rayAngle:  rAngle,
+		rayLength: maxDistance,
+		wallType:  0,
+		wallFace:  wallFaceNone,
+		cellX:     -1,
+		cellY:     -1,
 	}
 }
 
-func (r Renderer) computeHorizontalCollision(x, y, rAngle float64) collisionDetail {
-	// Note: We don't check for rAngle == 0 or 180. This is because hen checking horizontal wall collisions, the ray will
-	//       never intersect with one when it is projected at 0 or 180 degrees.
-
-	// Convert the angle (in degrees) to radians because that's what the math library expects.
+// computeDoorCollision intersects a ray with a door's plane, which is recessed in the middle of the door's cell. Returns
+// false if the ray doesn't cross the plane within the cell or if it goes through the open part of the door. The door
+// slides towards the cell's higher coordinates, leaving the panel on [OpenFraction, 1). The texture is offset by the
+// door's open fraction so it moves with it.
+func (r Renderer) computeDoorCollision(x, y, rAngle float64, door data.DoorState) (collisionDetail, bool) {
 	rRad := rAngle * math.Pi / 180.0
-	rLength := 2048.0
-	wType := 0
-	startCoords := coordinates{
-		x: x,
-		y: y,
-	}
-	endCoords := coordinates{
-		x: 2048.0,
-		y: 2048.0,
-	}
-
-	if rAngle > 0.0 && rAngle < 180.0 {
-		for i := 0; i < 16; i++ {
-			// Coordinates of ray FROM initial position x, y
-			rY := y - float64(int(y)-i)
-			rX := (1 / math.Tan(rRad)) * rY
+	rCos := math.Cos(rRad)
+	rSin := math.Sin(rRad)
+
+	var distance, frac float64
+	var end coordinates
+	var face wallFace
+	var orientation int
+
+	if door.Horizontal {
+		// Rays at 0 or 180 degrees run parallel to the door.
+		if rAngle == 0.0 || rAngle == 180.0 || rAngle == 360.0 {
+			return collisionDetail{}, false
+		}
 
-			// -0.001 hack on y-yR necessary because collision checking is done on integer values (ex: >= 1, < 2). Ray should
-			//   be < 1 if player is standing right next to a wall in an adjacent square.
-			if collision, wall := r.gameManager.CheckWallCollision(x+rX, y-rY-0.001); collision {
-				rLength = rY / math.Sin(rRad)
-				wType = wall
-				endCoords.x = x + rX
-				endCoords.y = y - rY
+		planeY := float64(door.Y) + 0.5
+		distance = (y - planeY) / rSin
+		end = coordinates{x: x + distance*rCos, y: planeY}
+		frac = end.x - float64(door.X)
+		orientation = 1 // Horizontal wall collision
 
-				break
-			}
+		face = wallFaceSouth
+		if rSin < 0.0 {
+			face = wallFaceNorth
+		}
+	} else {
+		// Rays at 90 or 270 degrees run parallel to the door.
+		if rAngle == 90.0 || rAngle == 270.0 {
+			return collisionDetail{}, false
 		}
-	}
-
-	if rAngle > 180.0 && rAngle < 360.0 {
-		for i := 1; i < 16; i++ {
-			// Coordinates of ray FROM initial position x, y
-			rY := float64(int(y)+i) - y
-			rX := (1 / math.Tan(rRad)) * rY
 
-			// Substract rX because the Tangent is negative from 270 to 360 and positive from 180 to 270, which is the
-			//	 opposite of our reference coordinate system. (it is negative from 180 to 270 and positive from 270 to 360).
-			if collision, wall := r.gameManager.CheckWallCollision(x-rX, y+rY); collision {
-				rLength = rY / math.Sin(rRad)
-				wType = wall
-				endCoords.x = x - rX
-				endCoords.y = y + rY
+		planeX := float64(door.X) + 0.5
+		distance = (planeX - x) / rCos
+		end = coordinates{x: planeX, y: y - distance*rSin}
+		frac = end.y - float64(door.Y)
+		orientation = 0 // Vertical wall collision
 
-				break
-			}
+		face = wallFaceWest
+		if rCos < 0.0 {
+			face = wallFaceEast
 		}
 	}
 
+	if distance < 0.0 || frac < door.OpenFraction || frac >= 1.0 {
+		return collisionDetail{}, false
+	}
+
 	return collisionDetail{
-		rayStart:        startCoords,
-		rayEnd:          endCoords,
+		rayStart:        coordinates{x: x, y: y},
+		rayEnd:          end,
 		rayAngle:        rAngle,
-		rayLength:       math.Abs(rLength),
-		wallType:        wType,
-		wallOrientation: 1, // Horizontal wall collision
-	}
+		rayLength:       distance,
+		wallType:        door.TextureId,
+		wallOrientation: orientation,
+		wallFace:        face,
+		cellX:           door.X,
+		cellY:           door.Y,
+		textureOffset:   door.OpenFraction,
+		door:            true,
+	}, true
 }
 
 // FishEyeCompensation compensates for the perspective effect of the ray's angle relative to the player's direction.
@@ -254,46 +442,38 @@ func (r Renderer) computeWallRenderingDetails(x int) wallRenderingDetail {
 	height := float64(r.config.GetFbHeight())
 
 	rayAngle := r.computeRayAngle(x)
-	playerCoords := r.gameManager.GetPlayerCoords()
-	vCollision := r.computeVerticalCollision(playerCoords.PlayerX, playerCoords.PlayerY, rayAngle)
-	hCollision := r.computeHorizontalCollision(playerCoords.PlayerX, playerCoords.PlayerY, rayAngle)
+	playerCoords := r.snapshot.Player
+	collision := r.castRay(playerCoords.PlayerX, playerCoords.PlayerY, rayAngle, rayAxisBoth)
 
-	wallType := vCollision.wallType
-	wallOrientation := vCollision.wallOrientation
-	collisionRayLength := vCollision.rayLength
+	wallType := collision.wallType
+	if faces, ok := r.wallTextures[wallType]; ok && !collision.door {
+		wallType = faces[collision.wallFace]
+	}
+	wallOrientation := collision.wallOrientation
+	collisionRayLength := collision.rayLength
 
 	// We're only really interested in the factional part of collision coordinate because textures are mapped between
 	//	0 and 1. It has no value to keep the absolute world value of the collision.
 	var relCollisionTexCoord float64
 
-	// Validate if were computing the texture collision coordinate for WEST vertical walls. If so, flip the texture
-	//	coordinate so that the normal of the wall is facing towards the player and the texture renders in the correct
-	//	orientation. Failing to do this results in mirrored texture on the vertical axis.
-	if vCollision.rayEnd.x < vCollision.rayStart.x {
-		frac := vCollision.rayEnd.y - float64(int(vCollision.rayEnd.y))
+	// Flip the texture coordinate for EAST and NORTH faces so that the normal of the wall is facing towards the player
+	//	and the texture renders in the correct orientation. Failing to do this results in mirrored texture on the
+	//	vertical axis.
+	switch collision.wallFace {
+	case wallFaceEast:
+		frac := collision.rayEnd.y - float64(int(collision.rayEnd.y)) - collision.textureOffset
 		relCollisionTexCoord = 0.999999 - frac
-	} else {
-		relCollisionTexCoord = vCollision.rayEnd.y - float64(int(vCollision.rayEnd.y))
-	}
-
-	if hCollision.rayLength < vCollision.rayLength {
-		wallType = hCollision.wallType
-		wallOrientation = hCollision.wallOrientation
-		collisionRayLength = hCollision.rayLength
-
-		// Validate if were computing the texture collision coordinate for SOUTH horitontal walls. If so, flip the texture
-		//	coordinate so that the normal of the wall is facing towards the player and the texture renders in the correct
-		//	orientation. Failing to do this results in mirrored texture on the vertical axis.
-		if hCollision.rayEnd.y > hCollision.rayStart.y {
-			frac := hCollision.rayEnd.x - float64(int(hCollision.rayEnd.x))
-			relCollisionTexCoord = 0.999999 - frac
-		} else {
-			relCollisionTexCoord = hCollision.rayEnd.x - float64(int(hCollision.rayEnd.x))
-		}
+	case wallFaceWest:
+		relCollisionTexCoord = collision.rayEnd.y - float64(int(collision.rayEnd.y)) - collision.textureOffset
+	case wallFaceNorth:
+		frac := collision.rayEnd.x - float64(int(collision.rayEnd.x)) - collision.textureOffset
+		relCollisionTexCoord = 0.999999 - frac
+	case wallFaceSouth:
+		relCollisionTexCoord = collision.rayEnd.x - float64(int(collision.rayEnd.x)) - collision.textureOffset
 	}
 
 	// Fix the projection
-	rLength := r.fishEyeCompensation(playerCoords.PlayerAngle, rayAngle, collisionRayLength)
+	rLength := max(r.fishEyeCompensation(playerCoords.PlayerAngle, rayAngle, collisionRayLength), MIN_WALL_DISTANCE)
 
 	// Height will exceed the frame buffer height if we're closer than a ray length of 1 from the wall. This can be locked
 	//	down to FBHeight when texture mapping is diabled since we're applying solid colours.
@@ -304,16 +484,30 @@ func (r Renderer) computeWallRenderingDetails(x int) wallRenderingDetail {
 		wallDistance:                  rLength,
 		wallTextureId:                 wallType,
 		wallOrientation:               wallOrientation,
+		wallFace:                      collision.wallFace,
+		cellX:                         collision.cellX,
+		cellY:                         collision.cellY,
 		rayCollisionTextureCoordinate: relCollisionTexCoord,
 	}
 }
 
-func (r Renderer) drawVertical(x int) {
+func (r Renderer) drawVertical(thread, x int) {
 	renderingDetails := r.computeWallRenderingDetails(x)
 	h := renderingDetails.wallHeight
-	//o := renderingDetails.wallOrientation
 	tId := renderingDetails.wallTextureId
 	tCoord := renderingDetails.rayCollisionTextureCoordinate
+	// Face shading is applied along with the ambient light, so corners stay readable on flat coloured walls.
+	intensity := r.ambientLight * r.wallShading[renderingDetails.wallFace]
+
+	r.depthBuffer[x] = renderingDetails.wallDistance
+	r.automap.recordHit(x, renderingDetails.cellX, renderingDetails.cellY)
+
+	// Rays running out of distance, or leaving the map, don't hit a wall. The floor and ceiling fill the column.
+	if tId < 1 {
+		return
+	}
+
+	fogFactor := r.fog.computeFogFactor(renderingDetails.wallDistance)
 
 	renderHeightStart := (r.config.GetFbHeight() - h) >> 1
 	renderHeightEnd := (renderHeightStart + h)
@@ -323,7 +517,7 @@ func (r Renderer) drawVertical(x int) {
 		renderHeightEnd = r.config.GetFbHeight()
 	}
 
-	textureVertical := r.textureManager.GetTextureVertical(tId, h, tCoord)
+	textureVertical := r.textureManager.GetTextureVertical(thread, tId, h, tCoord)
 	for y := renderHeightStart; y < renderHeightEnd; y++ {
 
 		// Texture pixels need to be drawn from bottom up because of flipped OpenGL coordinate system.
@@ -334,42 +528,112 @@ func (r Renderer) drawVertical(x int) {
 		sTexSrc := (*uint32)(unsafe.Pointer(&textureVertical[textureIndex]))
 		fbDst := (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
 
-		*fbDst = r.applyLightingEffects(*sTexSrc)
+		*fbDst = r.fog.applyFog(shadeColor(*sTexSrc, intensity), fogFactor)
+	}
+}
+
+// computeFloorRayStep returns how much a ray travels on the floor (or ceiling) plane, in world coordinates, for each
+// unit of perpendicular distance from the player. This undoes the fish eye compensation so that floor and ceiling
+// points line up with the walls.
+func (r Renderer) computeFloorRayStep(rAngle float64) coordinates {
+	pAngle := r.snapshot.Player.PlayerAngle
+	rRad := rAngle * math.Pi / 180.0
+	compensation := math.Cos((rAngle - pAngle) * math.Pi / 180.0)
 
-		// TODO: Add filter to restore the orientation shading effect
-		//// We devide by two if the orientation is a vertical wall.
-		//r.frameBuffer[fbIndex] = r.applyLightingEffects(textureVertical[textureIndex] >> o)
-		//r.frameBuffer[fbIndex+1] = r.applyLightingEffects(textureVertical[textureIndex+1] >> o)
-		//r.frameBuffer[fbIndex+2] = r.applyLightingEffects(textureVertical[textureIndex+2] >> o)
-		//r.frameBuffer[fbIndex+3] = textureVertical[textureIndex+3]
+	// Y is substracted because 0 on the Y axis is at the top.
+	return coordinates{
+		x: math.Cos(rRad) / compensation,
+		y: -math.Sin(rRad) / compensation,
 	}
 }
 
-func (r Renderer) drawCeiling(x int) {
-	rAngle := r.computeRayAngle(x)
-	skyVertTexture := r.textureManager.GetSkyTextureVertical(rAngle)
-	halfHeight := r.config.GetFbHeight() >> 1
+// lookupCellTexture returns the texture id of a cell in a per cell texture grid, 0 if the cell is outside of the grid.
+func (r Renderer) lookupCellTexture(textures [][]int, x, y float64) int {
+	ix := int(x)
+	iy := int(y)
+
+	if x < 0.0 || y < 0.0 || iy >= len(textures) || ix >= len(textures[iy]) {
+		return 0
+	}
 
-	for y := r.config.GetFbHeight() - 1; y >= halfHeight; y-- {
-		skyTexIndex := ((r.config.GetFbHeight() - 1) - y) << 2
-		fbIndex := (x + y*r.config.GetFbWidth()) << 2
+	return textures[iy][ix]
+}
+
+func (r Renderer) drawCeiling(thread, x int) {
+	rAngle := r.computeRayAngle(x)
+	skyVertTexture := r.textureManager.GetSkyTextureVertical(thread, rAngle)
+	height := r.config.GetFbHeight()
+	halfHeight := height >> 1
+
+	// Only cast the ceiling if the level has ceiling textures, the sky covers everything otherwise.
+	var rayStep coordinates
+	playerCoords := r.snapshot.Player
+	if r.ceilingTextures != nil {
+		rayStep = r.computeFloorRayStep(rAngle)
+	}
 
-		sTexSrc := (*uint32)(unsafe.Pointer(&skyVertTexture[skyTexIndex]))
+	// y is the row from the top of the screen.
+	for y := 0; y < halfHeight; y++ {
+		fbIndex := (x + (height-1-y)*r.config.GetFbWidth()) << 2
 		fbDst := (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
 
+		if r.ceilingTextures != nil {
+			// Perpendicular distance to the ceiling, using the same projection as the walls. Sample the middle of the pixel.
+			distance := float64(height) / (2.0 * (float64(halfHeight-y) - 0.5))
+			cX := playerCoords.PlayerX + distance*rayStep.x
+			cY := playerCoords.PlayerY + distance*rayStep.y
+
+			if tId := r.lookupCellTexture(r.ceilingTextures, cX, cY); tId > 0 {
+				texel := r.textureManager.GetTexturePixel(tId, cX-math.Floor(cX), cY-math.Floor(cY))
+				*fbDst = r.fog.applyFog(r.applyLightingEffects(texel), r.rowFogFactors[halfHeight-1-y])
+
+				continue
+			}
+		}
+
+		// Cells without a ceiling fall back to the sky.
+		sTexSrc := (*uint32)(unsafe.Pointer(&skyVertTexture[y<<2]))
 		*fbDst = *sTexSrc
 	}
 }
 
-func (r Renderer) drawFloor() {
-	height := r.config.GetFbHeight() >> 1
-	for x := 0; x < r.config.GetFbWidth(); x++ {
-		for y := height; y >= 0; y-- {
+func (r Renderer) drawFloor(x int) {
+	height := r.config.GetFbHeight()
+	halfHeight := height >> 1
+
+	// Flat floor colour when the level doesn't have floor textures.
+	if r.floorTextures == nil {
+		for y := halfHeight; y >= 0; y-- {
 			fbIndex := (x + y*r.config.GetFbWidth()) << 2
+			// Rows away from the horizon, counted from the top of the screen.
+			row := max(height-1-y-halfHeight, 0)
 
 			fbDst := (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
-			*fbDst = r.applyLightingEffects(0xFF333333)
+			*fbDst = r.fog.applyFog(r.applyLightingEffects(0xFF333333), r.rowFogFactors[row])
+		}
+
+		return
+	}
+
+	rAngle := r.computeRayAngle(x)
+	rayStep := r.computeFloorRayStep(rAngle)
+	playerCoords := r.snapshot.Player
+
+	// y is the row from the top of the screen.
+	for y := halfHeight; y < height; y++ {
+		// Perpendicular distance to the floor, using the same projection as the walls. Sample the middle of the pixel.
+		distance := float64(height) / (2.0 * (float64(y-halfHeight) + 0.5))
+		fX := playerCoords.PlayerX + distance*rayStep.x
+		fY := playerCoords.PlayerY + distance*rayStep.y
+
+		texel := uint32(0xFF333333)
+		if tId := r.lookupCellTexture(r.floorTextures, fX, fY); tId > 0 {
+			texel = r.textureManager.GetTexturePixel(tId, fX-math.Floor(fX), fY-math.Floor(fY))
 		}
+
+		fbIndex := (x + (height-1-y)*r.config.GetFbWidth()) << 2
+		fbDst := (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
+		*fbDst = r.fog.applyFog(r.applyLightingEffects(texel), r.rowFogFactors[y-halfHeight])
 	}
 }
 
@@ -380,25 +644,59 @@ func (r *Renderer) clearFrameBuffer() {
 	}
 }
 
-// Draw draws the game to the frame buffer.
-func (r Renderer) Draw() []uint8 {
-	// Only measure and display FPS if it's enabled
-	if r.config.IsDisplayFpsEnabled() {
-		r.metrics.start()
-		defer func() {
-			r.metrics.stop()
-			fmt.Printf("\rFPS: %.2f  ", r.metrics.getValue())
-		}()
+// Draw draws the game to the frame buffer. The world snapshot is taken once so the whole frame sees the same world.
+func (r *Renderer) Draw() []uint8 {
+	//r.clearFrameBuffer()
+
+	r.snapshot = r.gameManager.GetWorldSnapshot()
+	r.textureManager.SetAnimationTime(r.snapshot.Elapsed)
+
+	start := time.Now()
+	spriteDetails := r.prepareSprites()
+	r.timeStage(metrics.STAGE_SPRITES, start)
+
+	r.pool.run(r.strips, spriteDetails)
+
+	start = time.Now()
+	r.automap.markSeenCells()
+	r.drawMapOverlay()
+	r.timeStage(metrics.STAGE_OVERLAY, start)
+
+	return r.frameBuffer
+}
+
+// timeStage adds the time since start to a stage of the frame, when metrics are recorded.
+func (r *Renderer) timeStage(stage metrics.Stage, start time.Time) {
+	if r.metrics != nil {
+		r.metrics.Time(stage, start)
 	}
+}
 
-	//r.clearFrameBuffer()
-	r.drawFloor()
+// GetFrameSnapshot returns the world snapshot the last frame was drawn from.
+func (r *Renderer) GetFrameSnapshot() data.WorldSnapshot {
+	return r.snapshot
+}
+
+// drawStrip draws the floor, ceiling, walls and then sprites of a strip of columns. Strips don't overlap, they can be
+// drawn concurrently. Each stage covers the whole strip before the next one starts, so it's timed once per strip.
+func (r Renderer) drawStrip(thread int, strip columnStrip) {
+	timer := r.metrics.ThreadTimer(thread)
 
-	// Draw walls
-	for x := 0; x < r.config.GetFbWidth(); x++ {
-		r.drawCeiling(x)
-		r.drawVertical(x)
+	for x := strip.start; x < strip.end; x++ {
+		r.drawFloor(x)
 	}
+	timer.Lap(metrics.STAGE_FLOOR)
 
-	return r.frameBuffer
+	for x := strip.start; x < strip.end; x++ {
+		r.drawCeiling(thread, x)
+	}
+	timer.Lap(metrics.STAGE_CEILING)
+
+	for x := strip.start; x < strip.end; x++ {
+		r.drawVertical(thread, x)
+	}
+	timer.Lap(metrics.STAGE_WALLS)
+
+	r.drawSprites(strip)
+	timer.Lap(metrics.STAGE_SPRITES)
 }
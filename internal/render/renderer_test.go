@@ -5,6 +5,8 @@ import (
 
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
 	"github.com/rebay1982/redcaster/internal/game"
@@ -15,6 +17,8 @@ const (
 	FB_HEIGHT = 480
 )
 
+var gameConfig = config.NewGameConfiguration(config.TICK_RATE, config.MOVE_SPEED, config.TURN_SPEED)
+
 func Test_RendererCalculateRayAngle(t *testing.T) {
 	var tManager TextureManager = nil
 
@@ -83,9 +87,10 @@ func Test_RendererCalculateRayAngle(t *testing.T) {
 					PlayerAngle: tc.pAngle,
 				},
 			}
-			game := game.NewGame(levelData, nil)
-			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, tc.fov)
+			game := game.NewGame(gameConfig, levelData, nil)
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, tc.fov, false)
 			r := NewRenderer(config, &game, tManager, levelData)
+			defer r.Close()
 
 			got := r.computeRayAngle(tc.screenColumn)
 
@@ -119,7 +124,7 @@ func Test_RendererCalculateVerticalCollision(t *testing.T) {
 			{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
 		},
 	}
-	game := game.NewGame(levelData, nil)
+	game := game.NewGame(gameConfig, levelData, nil)
 
 	testCases := []struct {
 		name     string
@@ -625,8 +630,9 @@ func Test_RendererCalculateVerticalCollision(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0)
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0, false)
 			r := NewRenderer(config, &game, tManager, data.LevelData{})
+			defer r.Close()
 
 			got := r.computeVerticalCollision(tc.pX, tc.pY, tc.rAngle)
 
@@ -688,7 +694,7 @@ func Test_RendererCalculateHorizontalCollision(t *testing.T) {
 			{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
 		},
 	}
-	game := game.NewGame(levelData, nil)
+	game := game.NewGame(gameConfig, levelData, nil)
 	var tManager TextureManager = nil
 
 	testCases := []struct {
@@ -1176,8 +1182,9 @@ func Test_RendererCalculateHorizontalCollision(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0)
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0, false)
 			r := NewRenderer(config, &game, tManager, data.LevelData{})
+			defer r.Close()
 
 			got := r.computeHorizontalCollision(tc.pX, tc.pY, tc.rAngle)
 
@@ -1218,6 +1225,369 @@ func Test_RendererCalculateHorizontalCollision(t *testing.T) {
 	}
 }
 
+func Test_RendererCastRay(t *testing.T) {
+	// 64x64 open level, only the border has walls. The inner wall at (40, 10) has a different type.
+	levelMap := make([][]int, 64)
+	for y := range levelMap {
+		levelMap[y] = make([]int, 64)
+		for x := range levelMap[y] {
+			if x == 0 || y == 0 || x == 63 || y == 63 {
+				levelMap[y][x] = 1
+			}
+		}
+	}
+	levelMap[10][40] = 2
+
+	levelData := data.LevelData{
+		Map: levelMap,
+	}
+	game := game.NewGame(gameConfig, levelData, nil)
+	var tManager TextureManager = nil
+
+	testCases := []struct {
+		name        string
+		pX, pY      float64
+		rAngle      float64
+		maxDistance float64
+		expected    collisionDetail
+	}{
+		{
+			name:        "2_2_pos_0_degrees_far_wall",
+			pX:          2.0,
+			pY:          2.0,
+			rAngle:      0.0,
+			maxDistance: config.MAX_RAY_DISTANCE,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 63.0,
+					y: 2.0,
+				},
+				rayLength: 61.0,
+				wallType:  1,
+				wallFace:  wallFaceWest,
+			},
+		},
+		{
+			name:        "2_2_pos_180_degrees_near_wall",
+			pX:          2.0,
+			pY:          2.0,
+			rAngle:      180.0,
+			maxDistance: config.MAX_RAY_DISTANCE,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 1.0,
+					y: 2.0,
+				},
+				rayLength: 1.0,
+				wallType:  1,
+				wallFace:  wallFaceEast,
+			},
+		},
+		{
+			name:        "40_50_pos_90_degrees_inner_wall",
+			pX:          40.5,
+			pY:          50.0,
+			rAngle:      90.0,
+			maxDistance: config.MAX_RAY_DISTANCE,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 40.5,
+					y: 11.0,
+				},
+				rayLength: 39.0,
+				wallType:  2,
+				wallFace:  wallFaceSouth,
+			},
+		},
+		{
+			name:        "32_40_pos_315_degrees_diagonal",
+			pX:          32.5,
+			pY:          40.5,
+			rAngle:      315.0,
+			maxDistance: config.MAX_RAY_DISTANCE,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 55.0,
+					y: 63.0,
+				},
+				rayLength: 31.819805153,
+				wallType:  1,
+				wallFace:  wallFaceNorth,
+			},
+		},
+		{
+			name:        "2_2_pos_0_degrees_max_distance",
+			pX:          2.0,
+			pY:          2.0,
+			rAngle:      0.0,
+			maxDistance: 20.0,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 20.0,
+					y: 20.0,
+				},
+				rayLength: 20.0,
+				wallType:  0,
+				wallFace:  wallFaceNone,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0, false)
+			config.SetMaxRayDistance(tc.maxDistance)
+			r := NewRenderer(config, &game, tManager, data.LevelData{})
+			defer r.Close()
+
+			got := r.castRay(tc.pX, tc.pY, tc.rAngle, rayAxisBoth)
+
+			// End
+			if !approximately(tc.expected.rayEnd.x, got.rayEnd.x) ||
+				!approximately(tc.expected.rayEnd.y, got.rayEnd.y) {
+				t.Errorf("Expected End (%f, %f), got (%f, %f)",
+					tc.expected.rayEnd.x,
+					tc.expected.rayEnd.y,
+					got.rayEnd.x,
+					got.rayEnd.y)
+			}
+
+			// Length
+			if !approximately(tc.expected.rayLength, got.rayLength) {
+				t.Errorf("Expected Length %f, got %f", tc.expected.rayLength, got.rayLength)
+			}
+
+			if tc.expected.wallType != got.wallType {
+				t.Errorf("Expected Walltype of %d, got %d", tc.expected.wallType, got.wallType)
+			}
+
+			if tc.expected.wallFace != got.wallFace {
+				t.Errorf("Expected wall face %d, got %d", tc.expected.wallFace, got.wallFace)
+			}
+		})
+	}
+}
+
+func Test_RendererComputeDoorCollision(t *testing.T) {
+	var tManager TextureManager = nil
+	game := game.NewGame(gameConfig, data.LevelData{}, nil)
+
+	testCases := []struct {
+		name     string
+		pX, pY   float64
+		rAngle   float64
+		door     data.DoorState
+		wantHit  bool
+		expected collisionDetail
+	}{
+		{
+			name:   "horizontal_closed",
+			pX:     2.5,
+			pY:     4.0,
+			rAngle: 90.0,
+			door: data.DoorState{
+				X:          2,
+				Y:          2,
+				TextureId:  3,
+				Horizontal: true,
+			},
+			wantHit: true,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 2.5,
+					y: 2.5,
+				},
+				rayLength: 1.5,
+				wallType:  3,
+				wallFace:  wallFaceSouth,
+			},
+		},
+		{
+			name:   "horizontal_partially_open",
+			pX:     2.8,
+			pY:     1.0,
+			rAngle: 270.0,
+			door: data.DoorState{
+				X:            2,
+				Y:            2,
+				TextureId:    3,
+				Horizontal:   true,
+				OpenFraction: 0.5,
+			},
+			wantHit: true,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 2.8,
+					y: 2.5,
+				},
+				rayLength:     1.5,
+				wallType:      3,
+				wallFace:      wallFaceNorth,
+				textureOffset: 0.5,
+			},
+		},
+		{
+			name:   "horizontal_through_open_part",
+			pX:     2.5,
+			pY:     4.0,
+			rAngle: 90.0,
+			door: data.DoorState{
+				X:            2,
+				Y:            2,
+				TextureId:    3,
+				Horizontal:   true,
+				OpenFraction: 0.6,
+			},
+			wantHit: false,
+		},
+		{
+			name:   "horizontal_parallel",
+			pX:     1.0,
+			pY:     2.5,
+			rAngle: 0.0,
+			door: data.DoorState{
+				X:          2,
+				Y:          2,
+				TextureId:  3,
+				Horizontal: true,
+			},
+			wantHit: false,
+		},
+		{
+			name:   "vertical_closed",
+			pX:     3.0,
+			pY:     3.5,
+			rAngle: 180.0,
+			door: data.DoorState{
+				X:         1,
+				Y:         3,
+				TextureId: 4,
+			},
+			wantHit: true,
+			expected: collisionDetail{
+				rayEnd: coordinates{
+					x: 1.5,
+					y: 3.5,
+				},
+				rayLength: 1.5,
+				wallType:  4,
+				wallFace:  wallFaceEast,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0, false)
+			r := NewRenderer(config, &game, tManager, data.LevelData{})
+			defer r.Close()
+
+			got, hit := r.computeDoorCollision(tc.pX, tc.pY, tc.rAngle, tc.door)
+
+			if hit != tc.wantHit {
+				t.Fatalf("Expected hit %t, got %t", tc.wantHit, hit)
+			}
+
+			if !hit {
+				return
+			}
+
+			if !approximately(tc.expected.rayEnd.x, got.rayEnd.x) ||
+				!approximately(tc.expected.rayEnd.y, got.rayEnd.y) {
+				t.Errorf("Expected End (%f, %f), got (%f, %f)",
+					tc.expected.rayEnd.x,
+					tc.expected.rayEnd.y,
+					got.rayEnd.x,
+					got.rayEnd.y)
+			}
+
+			if !approximately(tc.expected.rayLength, got.rayLength) {
+				t.Errorf("Expected Length %f, got %f", tc.expected.rayLength, got.rayLength)
+			}
+
+			if tc.expected.wallType != got.wallType {
+				t.Errorf("Expected Walltype of %d, got %d", tc.expected.wallType, got.wallType)
+			}
+
+			if tc.expected.wallFace != got.wallFace {
+				t.Errorf("Expected wall face %d, got %d", tc.expected.wallFace, got.wallFace)
+			}
+
+			if !approximately(tc.expected.textureOffset, got.textureOffset) {
+				t.Errorf("Expected texture offset %f, got %f", tc.expected.textureOffset, got.textureOffset)
+			}
+		})
+	}
+}
+
+func Test_RendererComputeFloorRayStep(t *testing.T) {
+	var tManager TextureManager = nil
+
+	testCases := []struct {
+		name     string
+		pAngle   float64
+		rAngle   float64
+		expected coordinates
+	}{
+		{
+			name:   "player_look_0_ray_0",
+			pAngle: 0.0,
+			rAngle: 0.0,
+			expected: coordinates{
+				x: 1.0,
+				y: 0.0,
+			},
+		},
+		{
+			name:   "player_look_90_ray_90",
+			pAngle: 90.0,
+			rAngle: 90.0,
+			expected: coordinates{
+				x: 0.0,
+				y: -1.0,
+			},
+		},
+		{
+			name:   "player_look_0_ray_45",
+			pAngle: 0.0,
+			rAngle: 45.0,
+			expected: coordinates{
+				x: 1.0,
+				y: -1.0,
+			},
+		},
+		{
+			name:   "player_look_180_ray_150",
+			pAngle: 180.0,
+			rAngle: 150.0,
+			expected: coordinates{
+				x: -1.0,
+				y: -0.577350269,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			levelData := data.LevelData{
+				PlayerCoordData: data.PlayerCoordData{
+					PlayerAngle: tc.pAngle,
+				},
+			}
+			game := game.NewGame(gameConfig, levelData, nil)
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 64.0, false)
+			r := NewRenderer(config, &game, tManager, levelData)
+			defer r.Close()
+
+			got := r.computeFloorRayStep(tc.rAngle)
+
+			if !approximately(tc.expected.x, got.x) || !approximately(tc.expected.y, got.y) {
+				t.Errorf("Expected (%f, %f), got (%f, %f)", tc.expected.x, tc.expected.y, got.x, got.y)
+			}
+		})
+	}
+}
+
 func approximately(x, y float64) bool {
 	const tolerance = 0.000001
 	epsilon := math.Nextafter(1.0, 2.0) - 1.0
@@ -1225,3 +1595,19 @@ func approximately(x, y float64) bool {
 
 	return diff < math.Max(tolerance*math.Max(math.Abs(x), math.Abs(y)), epsilon*8)
 }
+
+func Test_NewWallTextures(t *testing.T) {
+	walls := []data.WallData{
+		{Type: 3, North: 1, East: 2},
+		{Type: 4, Texture: 2, West: 1},
+	}
+
+	expected := map[int][wallFaceCount]int{
+		3: {wallFaceNone: 3, wallFaceNorth: 1, wallFaceSouth: 3, wallFaceEast: 2, wallFaceWest: 3},
+		4: {wallFaceNone: 2, wallFaceNorth: 2, wallFaceSouth: 2, wallFaceEast: 2, wallFaceWest: 1},
+	}
+
+	if diff := cmp.Diff(expected, newWallTextures(walls)); diff != "" {
+		t.Errorf("Test failed\n%s\n", diff)
+	}
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"image"
+	"image/png"
+	"os"
+)
+
+// FrameBufferToImage converts a frame buffer to an image. Frame buffer rows are stored bottom up (OpenGL coordinate
+// system), they are flipped so the image reads top down.
+func FrameBufferToImage(frameBuffer []uint8, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rowSize := width << 2
+
+	for y := 0; y < height; y++ {
+		fbRow := (height - 1 - y) * rowSize
+		copy(img.Pix[y*img.Stride:y*img.Stride+rowSize], frameBuffer[fbRow:fbRow+rowSize])
+	}
+
+	return img
+}
+
+// WriteScreenshot writes a frame buffer to a PNG file.
+func WriteScreenshot(filename string, frameBuffer []uint8, width, height int) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, FrameBufferToImage(frameBuffer, width, height)); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
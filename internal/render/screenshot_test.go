@@ -0,0 +1,30 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_FrameBufferToImage(t *testing.T) {
+	// 2x2 frame buffer, bottom row first.
+	frameBuffer := []uint8{
+		0x01, 0x02, 0x03, 0xFF, 0x04, 0x05, 0x06, 0xFF, // Bottom row
+		0x07, 0x08, 0x09, 0xFF, 0x0A, 0x0B, 0x0C, 0xFF, // Top row
+	}
+
+	want := []uint8{
+		0x07, 0x08, 0x09, 0xFF, 0x0A, 0x0B, 0x0C, 0xFF,
+		0x01, 0x02, 0x03, 0xFF, 0x04, 0x05, 0x06, 0xFF,
+	}
+
+	got := FrameBufferToImage(frameBuffer, 2, 2)
+
+	if got.Bounds().Dx() != 2 || got.Bounds().Dy() != 2 {
+		t.Errorf("Expected a 2x2 image, got %v", got.Bounds())
+	}
+
+	if diff := cmp.Diff(want, got.Pix); diff != "" {
+		t.Errorf("Failed to validate image pixels: -want +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"math"
+	"sort"
+	"unsafe"
+
+	"github.com/rebay1982/redcaster/internal/data"
+)
+
+// computeSpriteRenderingDetails projects a sprite on the screen. Returns false if the sprite is behind the player.
+func (r Renderer) computeSpriteRenderingDetails(sprite data.SpriteData) (spriteRenderingDetail, bool) {
+	playerCoords := r.snapshot.Player
+	pRad := playerCoords.PlayerAngle * math.Pi / 180.0
+	pCos := math.Cos(pRad)
+	pSin := math.Sin(pRad)
+
+	dX := sprite.X - playerCoords.PlayerX
+	dY := sprite.Y - playerCoords.PlayerY
+
+	// Distance along the player's direction, which is the same fish eye compensated distance used for walls. Y is
+	//	substracted because 0 on the Y axis is at the top.
+	distance := dX*pCos - dY*pSin
+	if distance < 0.0001 {
+		return spriteRenderingDetail{}, false
+	}
+
+	// Distance to the left of the player's direction.
+	lateral := -dX*pSin - dY*pCos
+
+	// Same projection as the one used to precompute the ray angle offsets, a column's step is constant on the tangent.
+	fov := r.config.GetFieldOfView()
+	halfWidth := r.config.GetFbWidth() >> 1
+	oppositeRefLength := math.Tan((fov / 2) * math.Pi / 180)
+	oppositeStep := oppositeRefLength / float64(halfWidth)
+
+	screenCenter := (oppositeRefLength - lateral/distance) / oppositeStep
+	screenWidth := sprite.Scale / (distance * oppositeStep)
+
+	// Same projection as the walls: a sprite with a scale of 1 is as high as a wall. Sprites stand on the floor.
+	fbHeight := float64(r.config.GetFbHeight())
+	screenHeight := fbHeight * sprite.Scale / distance
+	screenBottom := fbHeight/2.0 + fbHeight/(2.0*distance) - fbHeight*sprite.VerticalOffset/distance
+
+	return spriteRenderingDetail{
+		spriteDistance:  distance,
+		spriteTextureId: sprite.TextureId,
+		screenLeft:      int(math.Floor(screenCenter - screenWidth/2.0)),
+		screenTop:       int(math.Floor(screenBottom - screenHeight)),
+		screenWidth:     int(screenWidth),
+		screenHeight:    int(screenHeight),
+	}, true
+}
+
+// prepareSprites projects the visible sprites and sorts them from furthest to closest.
+func (r Renderer) prepareSprites() []spriteRenderingDetail {
+	spriteDetails := r.spriteDetails[:0]
+
+	for _, sprite := range r.snapshot.Sprites {
+		if detail, visible := r.computeSpriteRenderingDetails(sprite); visible {
+			spriteDetails = append(spriteDetails, detail)
+		}
+	}
+
+	sort.Slice(spriteDetails, func(i, j int) bool {
+		return spriteDetails[i].spriteDistance > spriteDetails[j].spriteDistance
+	})
+
+	return spriteDetails
+}
+
+// drawSprites draws the strip's sprites on top of the walls. Sprite columns behind a wall and fully transparent texels
+// are skipped. Must be called after the strip's walls are drawn so the depth buffer is up to date.
+func (r Renderer) drawSprites(strip columnStrip) {
+	fbWidth := r.config.GetFbWidth()
+	fbHeight := r.config.GetFbHeight()
+
+	for _, detail := range strip.sprites {
+		if detail.screenWidth <= 0 || detail.screenHeight <= 0 {
+			continue
+		}
+
+		// Clip to the strip and frame buffer.
+		startX := max(detail.screenLeft, strip.start)
+		endX := min(detail.screenLeft+detail.screenWidth, strip.end)
+		startY := max(detail.screenTop, 0)
+		endY := min(detail.screenTop+detail.screenHeight, fbHeight)
+		fogFactor := r.fog.computeFogFactor(detail.spriteDistance)
+
+		for x := startX; x < endX; x++ {
+			if detail.spriteDistance >= r.depthBuffer[x] {
+				continue
+			}
+
+			texXCoord := float64(x-detail.screenLeft) / float64(detail.screenWidth)
+
+			for y := startY; y < endY; y++ {
+				texYCoord := float64(y-detail.screenTop) / float64(detail.screenHeight)
+				texel := r.textureManager.GetTexturePixel(detail.spriteTextureId, texXCoord, texYCoord)
+
+				// Skip transparent texels.
+				if texel>>24 == 0 {
+					continue
+				}
+
+				// Flipped OpenGL coordinate system, (0, 0) is bottom left.
+				fbIndex := (x + (fbHeight-1-y)*fbWidth) << 2
+				fbDst := (*uint32)(unsafe.Pointer(&r.frameBuffer[fbIndex]))
+				*fbDst = r.fog.applyFog(r.applyLightingEffects(texel), fogFactor)
+			}
+		}
+	}
+}
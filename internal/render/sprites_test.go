@@ -0,0 +1,116 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/rebay1982/redcaster/internal/config"
+	"github.com/rebay1982/redcaster/internal/data"
+	"github.com/rebay1982/redcaster/internal/game"
+)
+
+func Test_RendererComputeSpriteRenderingDetails(t *testing.T) {
+	var tManager TextureManager = nil
+
+	levelData := data.LevelData{
+		PlayerCoordData: data.PlayerCoordData{
+			PlayerX:     2.0,
+			PlayerY:     2.0,
+			PlayerAngle: 0.0,
+		},
+	}
+	game := game.NewGame(gameConfig, levelData, nil)
+
+	testCases := []struct {
+		name        string
+		sprite      data.SpriteData
+		wantVisible bool
+		expected    spriteRenderingDetail
+	}{
+		{
+			name: "sprite_in_front",
+			sprite: data.SpriteData{
+				X:         4.0,
+				Y:         2.0,
+				TextureId: 1,
+				Scale:     1.0,
+			},
+			wantVisible: true,
+			expected: spriteRenderingDetail{
+				spriteDistance:  2.0,
+				spriteTextureId: 1,
+				screenLeft:      240,
+				screenTop:       120,
+				screenWidth:     160,
+				screenHeight:    240,
+			},
+		},
+		{
+			name: "sprite_left_scaled_offset",
+			sprite: data.SpriteData{
+				X:              4.0,
+				Y:              1.0,
+				TextureId:      2,
+				Scale:          0.5,
+				VerticalOffset: 0.5,
+			},
+			wantVisible: true,
+			expected: spriteRenderingDetail{
+				spriteDistance:  2.0,
+				spriteTextureId: 2,
+				screenLeft:      120,
+				screenTop:       120,
+				screenWidth:     80,
+				screenHeight:    120,
+			},
+		},
+		{
+			name: "sprite_behind",
+			sprite: data.SpriteData{
+				X:         1.0,
+				Y:         2.0,
+				TextureId: 1,
+				Scale:     1.0,
+			},
+			wantVisible: false,
+			expected:    spriteRenderingDetail{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := config.NewRenderConfiguration(FB_WIDTH, FB_HEIGHT, 90.0, false)
+			r := NewRenderer(config, &game, tManager, levelData)
+			defer r.Close()
+
+			got, visible := r.computeSpriteRenderingDetails(tc.sprite)
+
+			if visible != tc.wantVisible {
+				t.Errorf("Expected visible %t, got %t", tc.wantVisible, visible)
+			}
+
+			if !approximately(tc.expected.spriteDistance, got.spriteDistance) {
+				t.Errorf("Expected distance %f, got %f", tc.expected.spriteDistance, got.spriteDistance)
+			}
+
+			if tc.expected.spriteTextureId != got.spriteTextureId {
+				t.Errorf("Expected texture id %d, got %d", tc.expected.spriteTextureId, got.spriteTextureId)
+			}
+
+			if tc.expected.screenLeft != got.screenLeft || tc.expected.screenTop != got.screenTop {
+				t.Errorf("Expected position (%d, %d), got (%d, %d)",
+					tc.expected.screenLeft,
+					tc.expected.screenTop,
+					got.screenLeft,
+					got.screenTop)
+			}
+
+			if tc.expected.screenWidth != got.screenWidth || tc.expected.screenHeight != got.screenHeight {
+				t.Errorf("Expected size %dx%d, got %dx%d",
+					tc.expected.screenWidth,
+					tc.expected.screenHeight,
+					got.screenWidth,
+					got.screenHeight)
+			}
+		})
+	}
+}
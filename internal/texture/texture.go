@@ -2,6 +2,7 @@ package texture
 
 import (
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/rebay1982/redcaster/internal/config"
@@ -10,11 +11,14 @@ import (
 )
 
 type TextureManager struct {
-	config                   config.RenderConfiguration
-	textureData              []data.TextureData
-	skyTextureData           []data.TextureData
-	textureVerticalBuffer    []uint8
-	skyTextureVerticalBuffer []uint8
+	config         config.RenderConfiguration
+	textureData    []data.TextureData
+	skyTextureData []data.TextureData
+	// Scratch buffers, one per rendering thread.
+	textureVerticalBuffers    [][]uint8
+	skyTextureVerticalBuffers [][]uint8
+	// Byte offset of the current frame of each texture, 0 for static textures.
+	frameOffsets []int
 }
 
 func NewTextureManager(config config.RenderConfiguration, levelData data.LevelData) TextureManager {
@@ -29,12 +33,12 @@ func NewTextureManager(config config.RenderConfiguration, levelData data.LevelDa
 	config.EnableSkyTextureMapping()
 
 	manager := TextureManager{
-		config:                   config,
-		textureData:              levelData.Textures,
-		skyTextureData:           skyTextures,
-		textureVerticalBuffer:    make([]uint8, config.GetFbHeight()<<2), // *4 (4 bytes per pixel)
-		skyTextureVerticalBuffer: make([]uint8, config.GetFbHeight()<<1), // /2 (half height) *4 (4 bytes per pixel)
+		config:         config,
+		textureData:    levelData.Textures,
+		skyTextureData: skyTextures,
+		frameOffsets:   make([]int, len(levelData.Textures)),
 	}
+	manager.allocateBuffers()
 
 	// Only if we have texture data should we enable texture mapping, even if it was explicitly requested.
 	if !(len(manager.textureData) > 0) {
@@ -54,6 +58,19 @@ func NewTextureManager(config config.RenderConfiguration, levelData data.LevelDa
 
 func (tm *TextureManager) Reconfigure(config config.RenderConfiguration) {
 	tm.config = config
+	tm.allocateBuffers()
+}
+
+// allocateBuffers allocates the scratch buffers for every rendering thread.
+func (tm *TextureManager) allocateBuffers() {
+	threads := tm.config.GetThreads()
+	tm.textureVerticalBuffers = make([][]uint8, threads)
+	tm.skyTextureVerticalBuffers = make([][]uint8, threads)
+
+	for i := 0; i < threads; i++ {
+		tm.textureVerticalBuffers[i] = make([]uint8, tm.config.GetFbHeight()<<2)    // *4 (4 bytes per pixel)
+		tm.skyTextureVerticalBuffers[i] = make([]uint8, tm.config.GetFbHeight()<<1) // /2 (half height) *4 (4 bytes per pixel)
+	}
 }
 
 func (tm TextureManager) validateSkyTextureConfiguration() {
@@ -76,8 +93,23 @@ func (tm TextureManager) validateSkyTextureConfiguration() {
 	)
 }
 
-func (tm TextureManager) GetSkyTextureVertical(rAngle float64) []uint8 {
-	skyVertBuffer := tm.skyTextureVerticalBuffer
+// SetAnimationTime selects the current frame of animated textures from the game clock. Must not be called while
+// rendering threads are reading textures.
+func (tm *TextureManager) SetAnimationTime(elapsed time.Duration) {
+	for i, texture := range tm.textureData {
+		if texture.FrameCount <= 1 || texture.FrameRate <= 0.0 {
+			continue
+		}
+
+		frame := int(elapsed.Seconds()*texture.FrameRate) % texture.FrameCount
+		tm.frameOffsets[i] = (frame * texture.Width * texture.Height) << 2
+	}
+}
+
+// GetSkyTextureVertical returns the sky column seen at the given ray angle. The returned buffer belongs to the calling
+// rendering thread and is overwritten on its next call.
+func (tm TextureManager) GetSkyTextureVertical(thread int, rAngle float64) []uint8 {
+	skyVertBuffer := tm.skyTextureVerticalBuffers[thread]
 
 	if tm.config.IsSkyTextureMappingEnabled() {
 		skyTexData := tm.skyTextureData[0]
@@ -110,17 +142,21 @@ func (tm TextureManager) GetSkyTextureVertical(rAngle float64) []uint8 {
 	return skyVertBuffer
 }
 
-func (tm TextureManager) GetTextureVertical(textureId int, renderHeight int, texColumnCoord float64) []uint8 {
-	texVertBuffer := tm.textureVerticalBuffer
+// GetTextureVertical returns a texture column scaled to the render height. The returned buffer belongs to the calling
+// rendering thread and is overwritten on its next call.
+func (tm TextureManager) GetTextureVertical(thread int, textureId int, renderHeight int, texColumnCoord float64) []uint8 {
+	texVertBuffer := tm.textureVerticalBuffers[thread]
 
-	fullRH := renderHeight
-	halfRH := fullRH >> 1
+	// The wall is centered vertically, rows outside of the buffer are clipped. These are the rows the renderer reads.
 	fullTBH := len(texVertBuffer) >> 2
-	halfTBH := fullTBH >> 1
+	startRow := (fullTBH - renderHeight) >> 1
+	firstRow := max(startRow, 0)
+	lastRow := min(startRow+renderHeight, fullTBH)
 
 	if tm.config.IsTextureMappingEnabled() {
 		// Get texture data
 		texture := tm.textureData[textureId-1]
+		frameOffset := tm.frameOffsets[textureId-1]
 		texHeight := texture.Height
 		texWidth := texture.Width
 		texColumn := int(float64(texWidth) * texColumnCoord)
@@ -128,48 +164,34 @@ func (tm TextureManager) GetTextureVertical(textureId int, renderHeight int, tex
 		// Sampling ratio for the texture to texture vertical buffer
 		texToTexVertBufferSampleRatio := float64(texHeight) / float64(renderHeight)
 
-		// Samples the center of the vertical to outer edges. This way seems convoluted but actually simplifies the
-		//	calculations quite a lot and always samples correctly whether the wall height to sample is smaller or larger than
-		//  the frame buffer height (larger happens when the player is close up against a wall).
-		for i := 0; i < halfRH && i < halfTBH; i++ {
-			rhIndexNeg := (halfRH - i)
-			rhIndexPos := (halfRH + i)
-			tvbIndexNeg := (halfTBH - i)
-			tvbIndexPos := (halfTBH + i)
-
-			// Sample from texture
-			textureRowNeg := int(float64(rhIndexNeg) * texToTexVertBufferSampleRatio)
-			textureRowPos := int(float64(rhIndexPos) * texToTexVertBufferSampleRatio)
-
+		for y := firstRow; y < lastRow; y++ {
 			// Sample from texture and write to texture vertical buffer.
-			texPixIndex := (texColumn + (textureRowNeg * texWidth)) << 2
-			tvbPixIndex := tvbIndexNeg << 2
+			textureRow := int(float64(y-startRow) * texToTexVertBufferSampleRatio)
+			texPixIndex := frameOffset + (texColumn+(textureRow*texWidth))<<2
 
 			texSrc := (*uint32)(unsafe.Pointer(&texture.Data[texPixIndex]))
-			texVertBuffDst := (*uint32)(unsafe.Pointer(&texVertBuffer[tvbPixIndex]))
-			*texVertBuffDst = *texSrc
-
-			texPixIndex = (texColumn + (textureRowPos * texWidth)) << 2
-			tvbPixIndex = tvbIndexPos << 2
-
-			texSrc = (*uint32)(unsafe.Pointer(&texture.Data[texPixIndex]))
-			texVertBuffDst = (*uint32)(unsafe.Pointer(&texVertBuffer[tvbPixIndex]))
+			texVertBuffDst := (*uint32)(unsafe.Pointer(&texVertBuffer[y<<2]))
 			*texVertBuffDst = *texSrc
 		}
 	} else {
-		for i := 0; i < halfRH && i < halfTBH; i++ {
-			tvbIndexNeg := (halfTBH - i)
-			tvbIndexPos := (halfTBH + i)
-
-			// Sample from texture and write to texture vertical buffer.
-			tvbPixIndex := tvbIndexNeg << 2
-			texVertBuffDst := (*uint32)(unsafe.Pointer(&texVertBuffer[tvbPixIndex]))
-			*texVertBuffDst = 0xFFCCCCCC
-
-			tvbPixIndex = tvbIndexPos << 2
-			texVertBuffDst = (*uint32)(unsafe.Pointer(&texVertBuffer[tvbPixIndex]))
+		for y := firstRow; y < lastRow; y++ {
+			texVertBuffDst := (*uint32)(unsafe.Pointer(&texVertBuffer[y<<2]))
 			*texVertBuffDst = 0xFFCCCCCC
 		}
 	}
 	return texVertBuffer
 }
+
+// GetTexturePixel returns a single pixel of a texture. The texture coordinates are expected to be between 0 and 1.
+func (tm TextureManager) GetTexturePixel(textureId int, texXCoord, texYCoord float64) uint32 {
+	if !tm.config.IsTextureMappingEnabled() {
+		return 0xFFCCCCCC
+	}
+
+	texture := tm.textureData[textureId-1]
+	texColumn := int(float64(texture.Width) * texXCoord)
+	texRow := int(float64(texture.Height) * texYCoord)
+	texPixIndex := tm.frameOffsets[textureId-1] + (texColumn+texRow*texture.Width)<<2
+
+	return *(*uint32)(unsafe.Pointer(&texture.Data[texPixIndex]))
+}
@@ -2,6 +2,7 @@ package texture
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rebay1982/redcaster/internal/config"
 	"github.com/rebay1982/redcaster/internal/data"
@@ -16,7 +17,7 @@ func Test_RendererValidateSkyTextureConfiguration(t *testing.T) {
 	}{
 		{
 			name:   "valid_texture",
-			config: config.NewRenderConfiguration(100, 50, 90.0),
+			config: config.NewRenderConfiguration(100, 50, 90.0, false),
 			skyTextureData: []data.TextureData{
 				{
 					Width:  100,
@@ -28,7 +29,7 @@ func Test_RendererValidateSkyTextureConfiguration(t *testing.T) {
 		},
 		{
 			name:   "invalid_texture_height",
-			config: config.NewRenderConfiguration(100, 200, 90.0),
+			config: config.NewRenderConfiguration(100, 200, 90.0, false),
 			skyTextureData: []data.TextureData{
 				{
 					Width:  100,
@@ -40,7 +41,7 @@ func Test_RendererValidateSkyTextureConfiguration(t *testing.T) {
 		},
 		{
 			name:   "invalid_texture_width",
-			config: config.NewRenderConfiguration(100, 50, 90.0),
+			config: config.NewRenderConfiguration(100, 50, 90.0, false),
 			skyTextureData: []data.TextureData{
 				{
 					Width:  123,
@@ -74,3 +75,58 @@ func Test_RendererValidateSkyTextureConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func Test_TextureManagerSetAnimationTime(t *testing.T) {
+	// Two 1x1 frames, black then white, at 2 frames per second.
+	levelData := data.LevelData{
+		Textures: []data.TextureData{
+			{
+				Width:      1,
+				Height:     1,
+				Data:       []uint8{0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+				FrameCount: 2,
+				FrameRate:  2.0,
+			},
+			{
+				Width:  1,
+				Height: 1,
+				Data:   []uint8{0x10, 0x20, 0x30, 0xFF},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		elapsed  time.Duration
+		expected [2]uint32
+	}{
+		{
+			name:     "first_frame",
+			elapsed:  0,
+			expected: [2]uint32{0xFF000000, 0xFF302010},
+		},
+		{
+			name:     "second_frame",
+			elapsed:  750 * time.Millisecond,
+			expected: [2]uint32{0xFFFFFFFF, 0xFF302010},
+		},
+		{
+			name:     "wraps_around",
+			elapsed:  1250 * time.Millisecond,
+			expected: [2]uint32{0xFF000000, 0xFF302010},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			texMngr := NewTextureManager(config.NewRenderConfiguration(4, 4, 90.0, false), levelData)
+			texMngr.SetAnimationTime(tc.elapsed)
+
+			for i, expected := range tc.expected {
+				if got := texMngr.GetTexturePixel(i+1, 0.0, 0.0); got != expected {
+					t.Errorf("Texture %d: expected pixel 0x%08X, got 0x%08X", i+1, expected, got)
+				}
+			}
+		})
+	}
+}